@@ -2,10 +2,11 @@ package exactonline
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
+	"strings"
 
 	"github.com/Recras/exactonline/httperror"
 )
@@ -13,14 +14,25 @@ import (
 const accountURI = "/api/v1/%d/crm/Accounts"
 
 type Account struct {
-	ID           string `json:",omitempty"`
-	Code         string `json:",omitempty"`
-	Name         string
-	AddressLine1 string `json:",omitempty"`
-	Postcode     string `json:",omitempty"`
-	City         string `json:",omitempty"`
-	SearchCode   string `json:",omitempty"`
-	Status       string `json:",omitempty"`
+	ID                    string `json:",omitempty"`
+	Code                  string `json:",omitempty"`
+	Name                  string
+	AddressLine1          string `json:",omitempty"`
+	Postcode              string `json:",omitempty"`
+	City                  string `json:",omitempty"`
+	Country               string `json:",omitempty"`
+	SearchCode            string `json:",omitempty"`
+	Status                string `json:",omitempty"`
+	Email                 string `json:",omitempty"`
+	Phone                 string `json:",omitempty"`
+	Website               string `json:",omitempty"`
+	VATNumber             string `json:",omitempty"`
+	ChamberOfCommerce     string `json:",omitempty"`
+	Language              string `json:",omitempty"`
+	PaymentConditionSales string `json:",omitempty"`
+	IsSales               bool   `json:",omitempty"`
+	Blocked               bool   `json:",omitempty"`
+	InvoicingAccount      string `json:",omitempty"`
 }
 
 type accounts struct {
@@ -29,20 +41,27 @@ type accounts struct {
 	} `json:"d"`
 }
 
+// ErrAccountNotFound is returned by the FindAccount methods. RecrasID is only
+// set by FindAccountByRecrasID.
 type ErrAccountNotFound struct {
 	Division int
 	RecrasID int
+	Filter   Filter
 }
 
 func (e ErrAccountNotFound) Error() string {
-	return fmt.Sprintf("Account not found for RecrasID `%#v` in Division %d", e.Division, e.RecrasID)
+	if e.RecrasID != 0 {
+		return fmt.Sprintf("Account not found for RecrasID `%d` in Division %d", e.RecrasID, e.Division)
+	}
+	return fmt.Sprintf("Account not found for `%s` in Division %d", e.Filter, e.Division)
 }
 
-func (c *Client) findAccountByFilter(recrasID int, filter string) (Account, error) {
-	u := fmt.Sprintf(accountURI, c.Division)
-	filt := url.Values{}
-	filt.Set("$filter", filter)
-	resp, err := c.Client.Get(fmt.Sprintf("%s?%s", u, filt.Encode()))
+func (c *Client) findAccount(ctx context.Context, filter Filter) (Account, error) {
+	if c.division == 0 {
+		return Account{}, ErrNoDivision
+	}
+	u := fmt.Sprintf(accountURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(filter).URL(u))
 	if err != nil {
 		return Account{}, err
 	}
@@ -58,24 +77,43 @@ func (c *Client) findAccountByFilter(recrasID int, filter string) (Account, erro
 		return Account{}, err
 	}
 	if len(out.D.Results) == 0 {
-		return Account{}, ErrAccountNotFound{c.Division, recrasID}
+		return Account{}, ErrAccountNotFound{Division: c.division, Filter: filter}
 	}
 
 	return out.D.Results[0], nil
 }
 
-func (c *Client) FindAccountByRecrasID(recrasID int) (Account, error) {
-	if c.Division == 0 {
-		return Account{}, ErrNoDivision
+func (c *Client) FindAccountByRecrasID(ctx context.Context, recrasID int) (Account, error) {
+	a, err := c.findAccount(ctx, Eq("SearchCode", fmt.Sprintf("K%d", recrasID)))
+	if e, ok := err.(ErrAccountNotFound); ok {
+		e.RecrasID = recrasID
+		return a, e
 	}
-	a, err := c.findAccountByFilter(recrasID, fmt.Sprintf("SearchCode eq 'K%d'", recrasID))
 	return a, err
 }
 
+func (c *Client) FindAccountByVATNumber(ctx context.Context, vn string) (Account, error) {
+	return c.findAccount(ctx, Eq("VATNumber", vn))
+}
+
+func (c *Client) FindAccountByChamberOfCommerce(ctx context.Context, coc string) (Account, error) {
+	return c.findAccount(ctx, Eq("ChamberOfCommerce", coc))
+}
+
+func (c *Client) FindAccountByEmail(ctx context.Context, email string) (Account, error) {
+	return c.findAccount(ctx, Eq("Email", email))
+}
+
+// FindAccountByCode finds the Account with code. Exact Online pads codes with
+// spaces, so they are trimmed before comparing.
+func (c *Client) FindAccountByCode(ctx context.Context, code string) (Account, error) {
+	return c.findAccount(ctx, Filter("trim(Code) eq "+quote(strings.TrimSpace(code))))
+}
+
 var ErrAccountNameRequired = errors.New("Field `Name` on type `Account` is mandatory")
 
-func (a *Account) Save(ecl *Client) error {
-	if ecl.Division == 0 {
+func (a *Account) Save(ctx context.Context, ecl *Client) error {
+	if ecl.division == 0 {
 		return ErrNoDivision
 	}
 
@@ -87,7 +125,7 @@ func (a *Account) Save(ecl *Client) error {
 		return err
 	}
 	bb := bytes.NewBuffer(bs)
-	resp, err := ecl.Client.Post(fmt.Sprintf(accountURI, ecl.Division), "application/json", bb)
+	resp, err := ecl.post(ctx, fmt.Sprintf(accountURI, ecl.division), bb)
 	if err != nil {
 		return err
 	}
@@ -103,3 +141,13 @@ func (a *Account) Save(ecl *Client) error {
 	*a = envelope["d"]
 	return nil
 }
+
+// Update sends the fields of a that differ from orig, the Account as it was
+// read from Exact Online
+func (a *Account) Update(ctx context.Context, c *Client, orig Account) error {
+	return c.update(ctx, accountURI, a.ID, orig, *a, "ID")
+}
+
+func (a *Account) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, accountURI, a.ID)
+}
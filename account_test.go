@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,10 +26,10 @@ func TestFindAccountByRecrasID(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	item, err := cl.FindAccountByRecrasID(12)
+	cl.division = 123
+	item, err := cl.FindAccountByRecrasID(context.Background(), 12)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -51,10 +52,10 @@ func TestFindAccountByRecrasID_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	_, err := cl.FindAccountByRecrasID(12)
+	cl.division = 123
+	_, err := cl.FindAccountByRecrasID(context.Background(), 12)
 	if !findBySearchCodeCalled {
 		t.Errorf("Expected Accounts API to be queried by SearchCode")
 	}
@@ -67,7 +68,7 @@ func TestFindAccountByRecrasID_NotFound(t *testing.T) {
 
 func TestFindAccountByRecrasID_NoDivision(t *testing.T) {
 	cl := Client{}
-	_, err := cl.FindAccountByRecrasID(12)
+	_, err := cl.FindAccountByRecrasID(context.Background(), 12)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -104,17 +105,17 @@ func TestSave(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
+	cl.division = 123
 
 	a := Account{}
-	if err := a.Save(cl); err != ErrAccountNameRequired {
+	if err := a.Save(context.Background(), cl); err != ErrAccountNameRequired {
 		t.Errorf("Expected ErrAccountNameRequired if Name is missing, got %#v", err)
 	}
 
 	a.Name = "Blabla"
-	if err := a.Save(cl); err != nil {
+	if err := a.Save(context.Background(), cl); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
 	if a.ID != "guid" {
@@ -125,8 +126,74 @@ func TestSave(t *testing.T) {
 func TestSaveAccount_NoDivision(t *testing.T) {
 	cl := &Client{}
 	a := Account{Name: "asdf"}
-	err := a.Save(cl)
+	err := a.Save(context.Background(), cl)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
 }
+
+func TestFindAccountByRecrasID_Cancelled(t *testing.T) {
+	called := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cl.FindAccountByRecrasID(ctx, 12); err == nil {
+		t.Errorf("Expected error for cancelled context")
+	}
+	if called {
+		t.Errorf("Expected no request to be sent for cancelled context")
+	}
+}
+
+func TestFindAccountBy(t *testing.T) {
+	var filter string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("$filter")
+		if filter == "Email eq 'unknown@example.com'" {
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID": "guid", "Name": "Recras", "IsSales": true}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+	ctx := context.Background()
+
+	cases := []struct {
+		find   func() (Account, error)
+		filter string
+	}{
+		{func() (Account, error) { return cl.FindAccountByVATNumber(ctx, "NL123456789B01") }, "VATNumber eq 'NL123456789B01'"},
+		{func() (Account, error) { return cl.FindAccountByChamberOfCommerce(ctx, "01234567") }, "ChamberOfCommerce eq '01234567'"},
+		{func() (Account, error) { return cl.FindAccountByEmail(ctx, "info@recras.nl") }, "Email eq 'info@recras.nl'"},
+		{func() (Account, error) { return cl.FindAccountByCode(ctx, "  12") }, "trim(Code) eq '12'"},
+	}
+	for _, cs := range cases {
+		a, err := cs.find()
+		if err != nil {
+			t.Errorf("Expected no error, got %#v", err)
+		}
+		if filter != cs.filter {
+			t.Errorf("Expected $filter to be %#v, got %#v", cs.filter, filter)
+		}
+		if a.ID != "guid" || !a.IsSales {
+			t.Errorf("Expected the account, got %#v", a)
+		}
+	}
+
+	_, err := cl.FindAccountByEmail(ctx, "unknown@example.com")
+	if e, ok := err.(ErrAccountNotFound); !ok || e.Filter != "Email eq 'unknown@example.com'" {
+		t.Errorf("Expected ErrAccountNotFound with filter, got %#v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+const addressURI = "/api/v1/%d/crm/Addresses"
+
+// Types of Address
+const (
+	AddressVisit    = 1
+	AddressPostal   = 2
+	AddressInvoice  = 3
+	AddressDelivery = 4
+)
+
+// Address is an additional address of an Account, e.g. its invoice address
+type Address struct {
+	ID           string `json:",omitempty"`
+	Account      string
+	Type         int
+	Main         bool   `json:",omitempty"`
+	AddressLine1 string `json:",omitempty"`
+	AddressLine2 string `json:",omitempty"`
+	Postcode     string `json:",omitempty"`
+	City         string `json:",omitempty"`
+	Country      string `json:",omitempty"`
+}
+
+var (
+	ErrAddressAccountRequired = errors.New("Field `Account` on type `Address` is mandatory")
+	ErrAddressTypeRequired    = errors.New("Field `Type` on type `Address` is mandatory")
+)
+
+func (c *Client) FindAddressesByAccount(ctx context.Context, account string) ([]Address, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Address{}
+	u := NewQuery().Filter(Eq("Account", GUID(account))).URL(fmt.Sprintf(addressURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (a *Address) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if a.Account == "" {
+		return ErrAddressAccountRequired
+	}
+	if a.Type == 0 {
+		return ErrAddressTypeRequired
+	}
+
+	bs, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(addressURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]Address{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*a = envelope["d"]
+	return nil
+}
+
+func (a *Address) Update(ctx context.Context, c *Client, orig Address) error {
+	return c.update(ctx, addressURI, a.ID, orig, *a, "ID")
+}
+
+func (a *Address) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, addressURI, a.ID)
+}
@@ -0,0 +1,46 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSaveAddress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/crm/Addresses" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["Type"] != float64(AddressInvoice) {
+			t.Errorf("Expected invoice address, got %#v", payload["Type"])
+		}
+		payload["ID"] = "address-guid"
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(map[string]interface{}{"d": payload})
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	a := Address{Account: "account-guid"}
+	if err := a.Save(context.Background(), cl); err != ErrAddressTypeRequired {
+		t.Errorf("Expected ErrAddressTypeRequired, got %#v", err)
+	}
+	a.Type = AddressInvoice
+	a.AddressLine1 = "Hoofdstraat 1"
+	if err := a.Save(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if a.ID != "address-guid" || a.AddressLine1 != "Hoofdstraat 1" {
+		t.Errorf("Expected address to be saved, got %#v", a)
+	}
+}
@@ -1,14 +1,19 @@
 package exactonline
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Recras/exactonline/httperror"
 	"golang.org/x/oauth2"
 )
 
@@ -48,14 +53,41 @@ func EnvConfig() Config {
 	}
 }
 
+// expiresIn is the token lifetime in seconds. Exact Online sends it as a
+// string when refreshing and as a number when exchanging a code.
+type expiresIn int
+
+func (e *expiresIn) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*e = 0
+		return nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return errors.New("auth: invalid expires_in " + string(b))
+	}
+	*e = expiresIn(i)
+	return nil
+}
+
 type exactToken struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int    `json:"expires_in,string"`
-	TokenType    string `json:"token_type"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    expiresIn `json:"expires_in"`
+	TokenType    string    `json:"token_type"`
+}
+
+func (et exactToken) token() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  et.AccessToken,
+		RefreshToken: et.RefreshToken,
+		TokenType:    et.TokenType,
+		Expiry:       time.Now().Add(time.Duration(et.ExpiresIn) * time.Second),
+	}
 }
 
-func (c Config) Exchange(code string) (*oauth2.Token, error) {
+func (c Config) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
 	if c.Oauth.ClientSecret == "" {
 		return nil, ErrNoClientSecret
 	}
@@ -65,25 +97,10 @@ func (c Config) Exchange(code string) (*oauth2.Token, error) {
 	data.Add("grant_type", "authorization_code")
 	data.Add("client_id", c.Oauth.ClientID)
 	data.Add("client_secret", c.Oauth.ClientSecret)
-
-	resp, err := http.PostForm(c.Oauth.Endpoint.TokenURL, data)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	tok := &oauth2.Token{}
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(tok)
-	if err != nil {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		return nil, errors.New("auth.Exchange: could not decode json: " + buf.String())
-	}
-	tok.Expiry = time.Now().Add(600 * time.Second)
-	return tok, nil
+	return c.postToken(ctx, "auth.Exchange", data)
 }
 
-func (c Config) refreshToken(t *oauth2.Token) (*oauth2.Token, error) {
+func (c Config) refreshToken(ctx context.Context, t *oauth2.Token) (*oauth2.Token, error) {
 	if c.Oauth.ClientSecret == "" {
 		return nil, ErrNoClientSecret
 	}
@@ -92,43 +109,97 @@ func (c Config) refreshToken(t *oauth2.Token) (*oauth2.Token, error) {
 	data.Add("client_secret", c.Oauth.ClientSecret)
 	data.Add("client_id", c.Oauth.ClientID)
 	data.Add("grant_type", "refresh_token")
-	resp, err := http.PostForm(c.Oauth.Endpoint.TokenURL, data)
+	return c.postToken(ctx, "auth.refreshToken", data)
+}
+
+// ErrEmptyToken is returned when Exact Online answers a token request without
+// an access or refresh token
+var ErrEmptyToken = errors.New("auth: token response has no access or refresh token")
+
+// postToken requests a token from the token endpoint. Anything but a 200 with
+// both an access and a refresh token is an error, so a failed request never
+// replaces a working refresh token.
+func (c Config) postToken(ctx context.Context, op string, data url.Values) (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.Oauth.Endpoint.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		e := httperror.New(resp)
+		// the request holds the client secret and refresh token
+		e.RequestBody = nil
+		return nil, e
+	}
 
-	et := &exactToken{}
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(et)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		return nil, errors.New("auth.refreshToken: could not decode json: " + buf.String())
+		return nil, err
 	}
-	return &oauth2.Token{
-		AccessToken:  et.AccessToken,
-		RefreshToken: et.RefreshToken,
-		TokenType:    et.TokenType,
-		Expiry:       time.Now().Add(time.Duration(et.ExpiresIn) * time.Second),
-	}, nil
+	et := &exactToken{}
+	if err := json.Unmarshal(body, et); err != nil {
+		return nil, errors.New(op + ": could not decode json: " + string(body))
+	}
+	if et.AccessToken == "" || et.RefreshToken == "" {
+		return nil, ErrEmptyToken
+	}
+	return et.token(), nil
 }
 
-type tokenSource struct {
-	token  oauth2.Token
-	config Config
+// expiryDelta is how long before its expiry a token is refreshed, so it does
+// not expire during a request
+const expiryDelta = 10 * time.Second
+
+// TokenSource hands out the access token of a Client, refreshing it when it
+// is about to expire. Exact Online invalidates the refresh token on every refresh, so
+// the new token is kept and passed to OnRefresh to be stored.
+// A TokenSource is safe for concurrent use.
+type TokenSource struct {
+	// OnRefresh is called with every refreshed token. When it fails, Token
+	// returns the error and OnRefresh is called again on the next Token call.
+	OnRefresh func(*oauth2.Token) error
+
+	mu      sync.Mutex
+	token   oauth2.Token
+	config  Config
+	unsaved bool
 }
 
-func (ts tokenSource) Token() (*oauth2.Token, error) {
-	if time.Now().Before(ts.token.Expiry) {
-		return &ts.token, nil
+// Token returns the current token, refreshing it when needed
+func (ts *TokenSource) Token() (*oauth2.Token, error) {
+	return ts.TokenContext(context.Background())
+}
+
+// TokenContext is Token with a context for the refresh request
+func (ts *TokenSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !time.Now().Add(expiryDelta).Before(ts.token.Expiry) {
+		tok, err := ts.config.refreshToken(ctx, &ts.token)
+		if err != nil {
+			return nil, err
+		}
+		ts.token = *tok
+		ts.unsaved = true
+	}
+	if ts.unsaved && ts.OnRefresh != nil {
+		tok := ts.token
+		if err := ts.OnRefresh(&tok); err != nil {
+			return nil, err
+		}
 	}
-	tok, err := ts.config.refreshToken(&ts.token)
-	return tok, err
+	ts.unsaved = false
+	tok := ts.token
+	return &tok, nil
 }
 
-func (c Config) NewTokenSource(refresh_token string) tokenSource {
-	tok := tokenSource{
+func (c Config) NewTokenSource(refresh_token string) *TokenSource {
+	tok := &TokenSource{
 		config: c,
 	}
 	tok.token.RefreshToken = refresh_token
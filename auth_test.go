@@ -1,12 +1,16 @@
 package exactonline
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Recras/exactonline/httperror"
 	"golang.org/x/oauth2"
 )
 
@@ -38,13 +42,13 @@ func TestExchange(t *testing.T) {
 	defer ts.Close()
 
 	c.Oauth.Endpoint.TokenURL = ts.URL
-	_, err := c.Exchange(`asdf`)
+	_, err := c.Exchange(context.Background(), `asdf`)
 	if err != ErrNoClientSecret {
 		t.Errorf(`Expected ErrNoClientSecret`)
 	}
 	c.Oauth.ClientID = `asdf`
 	c.Oauth.ClientSecret = `s3cr1t`
-	tok, err := c.Exchange(`asdf`)
+	tok, err := c.Exchange(context.Background(), `asdf`)
 
 	if !called {
 		t.Error(`Expected mock http server to be called`)
@@ -61,8 +65,8 @@ func TestExchange(t *testing.T) {
 	if tok.TokenType != `bearer` {
 		t.Errorf(`Expected bearer token type, got %#v`, tok.TokenType)
 	}
-	if (tok.Expiry == time.Time{}) {
-		t.Errorf(`Expected expiry to be set`)
+	if d := tok.Expiry.Sub(time.Now()); d < 590*time.Second || d > 600*time.Second {
+		t.Errorf(`Expected expiry to be taken from expires_in, got %s`, d)
 	}
 }
 
@@ -70,7 +74,7 @@ func TestRefreshNoClientSecret(t *testing.T) {
 	c := Config{Oauth: &oauth2.Config{}}
 	c.Oauth.ClientSecret = ""
 
-	if _, err := c.refreshToken(nil); err != ErrNoClientSecret {
+	if _, err := c.refreshToken(context.Background(), nil); err != ErrNoClientSecret {
 		t.Errorf(`Expected ErrNoClientSecret`)
 	}
 }
@@ -104,7 +108,7 @@ func TestRefreshWithClientSecret(t *testing.T) {
 	c.Oauth.ClientID = "asdfasdf"
 	c.Oauth.ClientSecret = "s3cr1t"
 
-	tok, err := c.refreshToken(&oauth2.Token{
+	tok, err := c.refreshToken(context.Background(), &oauth2.Token{
 		RefreshToken: "refreshtoken",
 	})
 	if err != nil {
@@ -131,10 +135,10 @@ func TestTokenSourceUnexpiredToken(t *testing.T) {
 	c := Config{Oauth: &oauth2.Config{}}
 	c.Oauth.Endpoint.TokenURL = mock.URL
 
-	ts := &tokenSource{
+	ts := &TokenSource{
 		token: oauth2.Token{
 			RefreshToken: "refreshtoken",
-			Expiry:       time.Now().Add(3 * time.Second),
+			Expiry:       time.Now().Add(time.Minute),
 		},
 		config: c,
 	}
@@ -147,6 +151,31 @@ func TestTokenSourceUnexpiredToken(t *testing.T) {
 	}
 }
 
+func TestTokenSourceAlmostExpiredToken(t *testing.T) {
+	mock := mockRefreshTokenServer(t)
+	defer mock.Close()
+
+	c := Config{Oauth: &oauth2.Config{}}
+	c.Oauth.Endpoint.TokenURL = mock.URL
+	c.Oauth.ClientID = "asdfasdf"
+	c.Oauth.ClientSecret = "s3cr1t"
+
+	ts := &TokenSource{
+		token: oauth2.Token{
+			RefreshToken: "refreshtoken",
+			Expiry:       time.Now().Add(3 * time.Second),
+		},
+		config: c,
+	}
+	tok, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if tok.Expiry.Sub(time.Now()) < time.Minute {
+		t.Errorf("Expected a token that expires within %s to be refreshed, got %#v", expiryDelta, tok)
+	}
+}
+
 func TestTokenSourceExpiredToken(t *testing.T) {
 	mock := mockRefreshTokenServer(t)
 	defer mock.Close()
@@ -156,7 +185,7 @@ func TestTokenSourceExpiredToken(t *testing.T) {
 	c.Oauth.ClientID = "asdfasdf"
 	c.Oauth.ClientSecret = "s3cr1t"
 
-	ts := &tokenSource{
+	ts := &TokenSource{
 		token: oauth2.Token{
 			AccessToken:  `accessToken`,
 			RefreshToken: `refreshtoken`,
@@ -190,3 +219,142 @@ func TestNewTokenSource(t *testing.T) {
 		t.Errorf("Expected RefreshToken value to be `refreshToken`, got %s", tok.token.RefreshToken)
 	}
 }
+
+func TestTokenSourceOnRefresh(t *testing.T) {
+	calls := 0
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"access_token": "accessToken", "token_type": "bearer", "expires_in": "600", "refresh_token": "refreshToken%d"}`, calls)
+	}))
+	defer mock.Close()
+
+	c := Config{Oauth: &oauth2.Config{ClientSecret: "s3cr1t"}}
+	c.Oauth.Endpoint.TokenURL = mock.URL
+	ts := c.NewTokenSource("refreshtoken")
+
+	saved := []string{}
+	fail := true
+	ts.OnRefresh = func(tok *oauth2.Token) error {
+		saved = append(saved, tok.RefreshToken)
+		if fail {
+			fail = false
+			return errors.New("database unavailable")
+		}
+		return nil
+	}
+
+	if _, err := ts.Token(); err == nil {
+		t.Errorf("Expected error of OnRefresh to be returned")
+	}
+	tok, err := ts.Token()
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if tok.RefreshToken != "refreshToken1" {
+		t.Errorf("Expected refreshed token to be cached, got %#v", tok.RefreshToken)
+	}
+	ts.Token()
+	if calls != 1 {
+		t.Errorf("Expected token to be refreshed once, got %d refreshes", calls)
+	}
+	if len(saved) != 2 || saved[1] != "refreshToken1" {
+		t.Errorf("Expected OnRefresh to be retried until it succeeds, got %#v", saved)
+	}
+}
+
+func TestTokenSourceConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		fmt.Fprintln(w, `{"access_token": "accessToken", "token_type": "bearer", "expires_in": 600, "refresh_token": "refreshToken"}`)
+	}))
+	defer mock.Close()
+
+	c := Config{Oauth: &oauth2.Config{ClientSecret: "s3cr1t"}}
+	c.Oauth.Endpoint.TokenURL = mock.URL
+	ts := c.NewTokenSource("refreshtoken")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ts.Token()
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected concurrent calls to share a single refresh, got %d refreshes", calls)
+	}
+}
+
+func TestTokenSourceRefreshRejected(t *testing.T) {
+	for name, respond := range map[string]func(w http.ResponseWriter){
+		"invalid_grant": func(w http.ResponseWriter) {
+			w.WriteHeader(400)
+			fmt.Fprintln(w, `{"error": "invalid_grant"}`)
+		},
+		"empty": func(w http.ResponseWriter) {
+			fmt.Fprintln(w, `{"access_token": "", "token_type": "bearer", "expires_in": "600", "refresh_token": ""}`)
+		},
+	} {
+		mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respond(w)
+		}))
+
+		c := Config{Oauth: &oauth2.Config{ClientSecret: "s3cr1t"}}
+		c.Oauth.Endpoint.TokenURL = mock.URL
+		ts := c.NewTokenSource("refreshtoken")
+		saved := 0
+		ts.OnRefresh = func(tok *oauth2.Token) error {
+			saved++
+			return nil
+		}
+
+		if _, err := ts.Token(); err == nil {
+			t.Errorf("%s: Expected an error", name)
+		}
+		if saved != 0 {
+			t.Errorf("%s: Expected a rejected refresh not to be persisted, got %d calls", name, saved)
+		}
+		if ts.token.RefreshToken != "refreshtoken" {
+			t.Errorf("%s: Expected the refresh token to be kept, got %#v", name, ts.token.RefreshToken)
+		}
+		mock.Close()
+	}
+}
+
+func TestExchangeRejected(t *testing.T) {
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprintln(w, `{"error": "invalid_grant"}`)
+	}))
+	defer mock.Close()
+
+	c := Config{Oauth: &oauth2.Config{ClientSecret: "s3cr1t"}}
+	c.Oauth.Endpoint.TokenURL = mock.URL
+	_, err := c.Exchange(context.Background(), "asdf")
+	if e, ok := err.(httperror.HTTPError); !ok || e.StatusCode != 400 {
+		t.Errorf("Expected HTTPError with status 400, got %#v", err)
+	} else if len(e.RequestBody) != 0 {
+		t.Errorf("Expected the request body with the client secret to be left out")
+	}
+}
+
+func TestTokenSourceCancelled(t *testing.T) {
+	mock := mockRefreshTokenServer(t)
+	defer mock.Close()
+
+	c := Config{Oauth: &oauth2.Config{ClientID: "asdfasdf", ClientSecret: "s3cr1t"}}
+	c.Oauth.Endpoint.TokenURL = mock.URL
+	ts := c.NewTokenSource("refreshtoken")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ts.TokenContext(ctx); err == nil {
+		t.Errorf("Expected the refresh to be cancelled")
+	}
+}
@@ -0,0 +1,182 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The bulk endpoints return 1000 rows per page instead of 60, the sync
+// endpoints return everything that changed after a Timestamp. Both require a
+// $select, which is derived from the json fields of the entity type.
+const (
+	bulkAccountsURI         = "/api/v1/%d/bulk/CRM/Accounts"
+	bulkItemsURI            = "/api/v1/%d/bulk/Logistics/Items"
+	bulkTransactionLinesURI = "/api/v1/%d/bulk/Financial/TransactionLines"
+
+	syncAccountsURI         = "/api/v1/%d/sync/CRM/Accounts"
+	syncItemsURI            = "/api/v1/%d/sync/Logistics/Items"
+	syncTransactionLinesURI = "/api/v1/%d/sync/Financial/TransactionLines"
+)
+
+// Timestamp is the row version Exact Online returns on the sync endpoints.
+// Store the Timestamp returned by a Sync method and pass it on the next call
+// to only fetch the changes made since then.
+type Timestamp int64
+
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" || s == "" {
+		*t = 0
+		return nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("exactonline: invalid Timestamp %s", b)
+	}
+	*t = Timestamp(i)
+	return nil
+}
+
+// selectFields returns the names of the json fields of struct v, skipping
+// fields that are not sent by Exact Online as plain values
+func selectFields(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	out := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		if f.Type.Kind() == reflect.Struct && strings.HasPrefix(f.Type.Name(), "deferred") {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+func (c *Client) bulk(ctx context.Context, uri string, entity interface{}, q *Query, fn func(json.RawMessage) error) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	bq := Query{}
+	if q != nil {
+		bq = *q
+	}
+	if len(bq.selects) == 0 {
+		bq.selects = selectFields(entity)
+	}
+	return c.Iterate(ctx, bq.URL(fmt.Sprintf(uri, c.division))).Each(fn)
+}
+
+func (c *Client) sync(ctx context.Context, uri string, entity interface{}, since Timestamp, fn func(json.RawMessage) error) (Timestamp, error) {
+	if c.division == 0 {
+		return since, ErrNoDivision
+	}
+	q := NewQuery().
+		Filter(Gt("Timestamp", int64(since))).
+		Select(append(selectFields(entity), "Timestamp")...)
+	last := since
+	err := c.Iterate(ctx, q.URL(fmt.Sprintf(uri, c.division))).Each(func(raw json.RawMessage) error {
+		var row struct {
+			Timestamp Timestamp
+		}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		if err := fn(raw); err != nil {
+			return err
+		}
+		if row.Timestamp > last {
+			last = row.Timestamp
+		}
+		return nil
+	})
+	return last, err
+}
+
+// BulkAccounts calls fn for every Account matching q, using the bulk endpoint.
+// q may be nil. Returning ErrStopIteration from fn stops early.
+func (c *Client) BulkAccounts(ctx context.Context, q *Query, fn func(Account) error) error {
+	return c.bulk(ctx, bulkAccountsURI, Account{}, q, func(raw json.RawMessage) error {
+		var a Account
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return err
+		}
+		return fn(a)
+	})
+}
+
+// BulkItems calls fn for every Item matching q, using the bulk endpoint.
+// q may be nil. Returning ErrStopIteration from fn stops early.
+func (c *Client) BulkItems(ctx context.Context, q *Query, fn func(Item) error) error {
+	return c.bulk(ctx, bulkItemsURI, Item{}, q, func(raw json.RawMessage) error {
+		var i Item
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return err
+		}
+		return fn(i)
+	})
+}
+
+// BulkTransactionLines calls fn for every TransactionLine matching q, using
+// the bulk endpoint. q may be nil. Returning ErrStopIteration from fn stops early.
+func (c *Client) BulkTransactionLines(ctx context.Context, q *Query, fn func(TransactionLine) error) error {
+	return c.bulk(ctx, bulkTransactionLinesURI, TransactionLine{}, q, func(raw json.RawMessage) error {
+		var l TransactionLine
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return err
+		}
+		return fn(l)
+	})
+}
+
+// SyncAccounts calls fn for every Account changed after since. It returns the
+// highest Timestamp of the rows fn handled without error, also when fn stops
+// early or fails, so the sync can be resumed from there.
+func (c *Client) SyncAccounts(ctx context.Context, since Timestamp, fn func(Account) error) (Timestamp, error) {
+	return c.sync(ctx, syncAccountsURI, Account{}, since, func(raw json.RawMessage) error {
+		var a Account
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return err
+		}
+		return fn(a)
+	})
+}
+
+// SyncItems calls fn for every Item changed after since. It returns the
+// highest Timestamp of the rows fn handled without error.
+func (c *Client) SyncItems(ctx context.Context, since Timestamp, fn func(Item) error) (Timestamp, error) {
+	return c.sync(ctx, syncItemsURI, Item{}, since, func(raw json.RawMessage) error {
+		var i Item
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return err
+		}
+		return fn(i)
+	})
+}
+
+// SyncTransactionLines calls fn for every TransactionLine changed after since.
+// It returns the highest Timestamp of the rows fn handled without error.
+func (c *Client) SyncTransactionLines(ctx context.Context, since Timestamp, fn func(TransactionLine) error) (Timestamp, error) {
+	return c.sync(ctx, syncTransactionLinesURI, TransactionLine{}, since, func(raw json.RawMessage) error {
+		var l TransactionLine
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return err
+		}
+		return fn(l)
+	})
+}
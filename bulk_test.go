@@ -0,0 +1,122 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSelectFields(t *testing.T) {
+	f := strings.Join(selectFields(SalesEntry{}), ",")
+	if !strings.HasPrefix(f, "EntryID,Customer,") {
+		t.Errorf("Expected json names to be used, got %s", f)
+	}
+	if strings.Contains(f, "SalesEntryLines") {
+		t.Errorf("Expected deferred fields to be skipped, got %s", f)
+	}
+}
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	for _, in := range []string{`12345`, `"12345"`} {
+		var ts Timestamp
+		if err := ts.UnmarshalJSON([]byte(in)); err != nil {
+			t.Errorf("Expected no error for %s, got %#v", in, err)
+		} else if ts != 12345 {
+			t.Errorf("Expected Timestamp to be 12345, got %d", ts)
+		}
+	}
+	var ts Timestamp
+	if err := ts.UnmarshalJSON([]byte(`"abc"`)); err == nil {
+		t.Errorf("Expected error for invalid Timestamp")
+	}
+}
+
+func TestBulkAccounts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/bulk/CRM/Accounts" {
+			t.Errorf("Expected bulk Accounts path, got %#v", r.URL.Path)
+		}
+		if s := r.URL.Query().Get("$select"); !strings.HasPrefix(s, "ID,Code,Name") {
+			t.Errorf("Expected $select to list Account fields, got %#v", s)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "Status eq 'C'" {
+			t.Errorf("Expected $filter to be passed, got %#v", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"guid1","Name":"A"},{"ID":"guid2","Name":"B"}]}}`)
+	}))
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+	names := []string{}
+	err := cl.BulkAccounts(context.Background(), NewQuery().Filter(Eq("Status", "C")), func(a Account) error {
+		names = append(names, a.Name)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if strings.Join(names, "") != "AB" {
+		t.Errorf("Expected both accounts, got %#v", names)
+	}
+}
+
+func TestBulkItems_NoDivision(t *testing.T) {
+	cl := Client{}
+	err := cl.BulkItems(context.Background(), nil, func(Item) error { return nil })
+	if err != ErrNoDivision {
+		t.Errorf("Expected ErrNoDivision, got %#v", err)
+	}
+}
+
+func TestSyncTransactionLines(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/sync/Financial/TransactionLines" {
+			t.Errorf("Expected sync TransactionLines path, got %#v", r.URL.Path)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "Timestamp gt 100" {
+			t.Errorf("Expected $filter on Timestamp, got %#v", f)
+		}
+		if s := r.URL.Query().Get("$select"); !strings.HasSuffix(s, ",Timestamp") {
+			t.Errorf("Expected $select to include Timestamp, got %#v", s)
+		}
+		fmt.Fprint(w, `{"d":{"results":[
+			{"ID":"guid1","Date":"/Date(1420070400000)/","AmountDC":10,"Timestamp":"150"},
+			{"ID":"guid2","Date":"/Date(1420070400000)/","AmountDC":5,"Timestamp":120}
+		]}}`)
+	}))
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+	total := 0.0
+	last, err := cl.SyncTransactionLines(context.Background(), 100, func(l TransactionLine) error {
+		total += l.AmountDC
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if total != 15 {
+		t.Errorf("Expected all lines to be passed, got total %f", total)
+	}
+	if last != 150 {
+		t.Errorf("Expected last Timestamp to be 150, got %d", last)
+	}
+
+	last, err = cl.SyncTransactionLines(context.Background(), 100, func(l TransactionLine) error {
+		return ErrStopIteration
+	})
+	if err != nil {
+		t.Errorf("Expected no error when stopping early, got %#v", err)
+	}
+	if last != 100 {
+		t.Errorf("Expected Timestamp to stay at 100 when no line was handled, got %d", last)
+	}
+}
@@ -1,43 +1,114 @@
 package exactonline
 
 import (
+	"context"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
 	Client      http.Client
-	Division    int
-	TokenSource tokenSource
+	TokenSource *TokenSource
+
+	division  int
+	transport *Transport
 }
 
-var ErrNoDivision = errors.New("exactonline/api: Client has no Division, use Client.GetDefaultDivision first")
+var ErrNoDivision = errors.New("exactonline/api: Client has no Division, use Client.DefaultDivision first")
 
 // NewClient creates a new Exact Online API client
 func (c Config) NewClient(tok oauth2.Token) *Client {
-	ts := tokenSource{
+	ts := &TokenSource{
 		token:  tok,
 		config: c,
 	}
-	_ = ts
 	b, _ := url.Parse(c.BaseURL)
+	t := &Transport{
+		BaseURL:    b,
+		MaxRetries: 3,
+		RetryWait:  time.Second,
+	}
 	return &Client{
 		TokenSource: ts,
 		Client: http.Client{
 			Transport: &oauth2.Transport{
-				Base:   &Transport{BaseURL: b},
+				Base:   t,
 				Source: ts,
 			},
 		},
+		transport: t,
 	}
 }
 
+// WithDivision returns a copy of c for division. The copy shares the HTTP
+// client, token source and rate limits with c but has its own Division, so
+// handles for several divisions can be used at the same time. All entity
+// methods work on the division of the Client they are called on.
+func (c *Client) WithDivision(division int) *Client {
+	cp := *c
+	cp.division = division
+	return &cp
+}
+
+// Division returns the division c works on, 0 when it has none
+func (c *Client) Division() int {
+	return c.division
+}
+
+func (c *Client) do(ctx context.Context, method, u string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.TokenSource != nil {
+		// refresh an expiring token with ctx, so the refresh is cancelled
+		// with the request
+		if _, err := c.TokenSource.TokenContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) get(ctx context.Context, u string) (*http.Response, error) {
+	return c.do(ctx, "GET", u, nil)
+}
+
+func (c *Client) post(ctx context.Context, u string, body io.Reader) (*http.Response, error) {
+	return c.do(ctx, "POST", u, body)
+}
+
+func (c *Client) put(ctx context.Context, u string, body io.Reader) (*http.Response, error) {
+	return c.do(ctx, "PUT", u, body)
+}
+
+func (c *Client) delete(ctx context.Context, u string) (*http.Response, error) {
+	return c.do(ctx, "DELETE", u, nil)
+}
+
+// Transport adds the headers Exact Online needs to every request. It keeps
+// track of the rate limits per division, waits when the minutely budget is
+// used up and retries requests that failed with a 429 or server error.
 type Transport struct {
 	Base    http.RoundTripper
 	BaseURL *url.URL
+
+	// MaxRetries is the number of times a failed request is retried
+	MaxRetries int
+	// RetryWait is the base wait before the first retry, it doubles with
+	// every following retry
+	RetryWait time.Duration
+
+	limits rateLimits
 }
 
 func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -55,8 +126,54 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		req2.Header.Add("Prefer", "return=representation")
 	}
 
-	res, err := t.base().RoundTrip(req2)
-	return res, err
+	division := divisionFromRequest(req2)
+	for attempt := 0; ; attempt++ {
+		if division != 0 {
+			wait, err := t.limits.reserve(division, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			if err := sleep(req2, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := t.base().RoundTrip(req2)
+		if err != nil {
+			return nil, err
+		}
+		if division != 0 {
+			t.limits.update(division, res.Header)
+		}
+
+		if attempt >= t.MaxRetries || !retryable(req2.Method, res.StatusCode) {
+			return res, nil
+		}
+		if req2.Body != nil {
+			if req2.GetBody == nil {
+				return res, nil
+			}
+			body, err := req2.GetBody()
+			if err != nil {
+				return res, nil
+			}
+			req3 := cloneRequest(req2)
+			req3.Body = body
+			req2 = req3
+		}
+
+		wait := t.backoff(attempt)
+		if res.StatusCode == http.StatusTooManyRequests {
+			if reset, ok := headerMillis(res.Header, "X-RateLimit-Minutely-Reset"); ok && reset.After(time.Now()) {
+				wait = reset.Sub(time.Now())
+			}
+		}
+		io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+		if err := sleep(req2, wait); err != nil {
+			return nil, err
+		}
+	}
 }
 
 func (t *Transport) base() http.RoundTripper {
@@ -24,7 +24,7 @@ func TestNewClient(t *testing.T) {
 	c := Config{}
 	cl := c.NewClient(oauth2.Token{
 		AccessToken: "opensesame",
-		Expiry:      time.Now().Add(2 * time.Second),
+		Expiry:      time.Now().Add(time.Minute),
 		TokenType:   "bearer",
 	})
 	cl.Client.Get(ts.URL)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/gob"
 	"errors"
 	"fmt"
@@ -101,8 +102,19 @@ func (app *Application) mux() *gorilla_mux.Router {
 
 	// manage existing link
 	router.Handle("/status", MustLogin(http.HandlerFunc(handlers.GetStatus))).Methods("GET")
+	router.Handle("/status/booking_mode", MustLogin(http.HandlerFunc(handlers.PostBookingMode))).Methods("POST")
+	router.Handle("/status/cost_rules", MustLogin(http.HandlerFunc(handlers.PostCostRule))).Methods("POST")
+	router.Handle("/status/cost_rules/delete", MustLogin(http.HandlerFunc(handlers.PostDeleteCostRule))).Methods("POST")
+	router.Handle("/status/closed_period_policy", MustLogin(http.HandlerFunc(handlers.PostClosedPeriodPolicy))).Methods("POST")
+	router.Handle("/status/payment_journals", MustLogin(http.HandlerFunc(handlers.PostPaymentJournals))).Methods("POST")
+	router.Handle("/status/revenue_account", MustLogin(http.HandlerFunc(handlers.PostRevenueAccount))).Methods("POST")
+	router.Handle("/status/division", MustLogin(http.HandlerFunc(handlers.PostDivisionMapping))).Methods("POST")
+	router.Handle("/status/setup", MustLogin(http.HandlerFunc(handlers.PostSetup))).Methods("POST")
 	router.Handle("/sync", MustLogin(http.HandlerFunc(handlers.GetSync))).Methods("GET")
 
+	// called by Exact Online, verified by signature instead of login
+	router.Handle("/webhooks/exact", handlers.ExactWebhooks()).Methods("POST")
+
 	router.HandleFunc("/login", handlers.GetLogin).Methods("GET")
 	router.HandleFunc("/login", handlers.PostLogin).Methods("POST")
 	router.HandleFunc("/logout", handlers.GetLogout).Methods("GET")
@@ -124,7 +136,13 @@ func main() {
 		logrus.Fatal(err.Error())
 	}
 
-	app.runTimedSync()
+	// ctx is cancelled once the server starts shutting down, which stops a
+	// running sync. Requests keep their own context, so the ones still being
+	// served can finish while the server drains.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app.runTimedSync(ctx)
 
 	serverAddress := libenv.EnvWithDefault("HTTP_ADDR", "127.0.0.1:8888")
 	certFile := libenv.EnvWithDefault("HTTP_CERT_FILE", "")
@@ -138,7 +156,11 @@ func main() {
 
 	srv := &graceful.Server{
 		Timeout: drainInterval,
-		Server:  &http.Server{Addr: serverAddress, Handler: middle},
+		Server: &http.Server{
+			Addr:    serverAddress,
+			Handler: middle,
+		},
+		ShutdownInitiated: cancel,
 	}
 
 	logrus.Infoln("Running HTTP server on " + serverAddress)
@@ -155,7 +177,7 @@ func main() {
 const beginHour = 3
 const beginMinute = 7
 
-func (app *Application) runTimedSync() {
+func (app *Application) runTimedSync(ctx context.Context) {
 	nexttick := time.Now()
 	if nexttick.Hour() >= beginHour && nexttick.Minute() > beginMinute {
 		nexttick = nexttick.AddDate(0, 0, 1)
@@ -166,7 +188,8 @@ func (app *Application) runTimedSync() {
 	logrus.Debugf("Waiting for %s", next3am.Sub(time.Now()))
 	time.AfterFunc(next3am.Sub(time.Now()), func() {
 		logrus.Debugf("Running sync")
-		ticker := time.Tick(24 * time.Hour)
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
 
 		syncAll := func(entry *logrus.Entry) {
 			creds, err := dal.FindAllCredentials(app.db)
@@ -176,13 +199,22 @@ func (app *Application) runTimedSync() {
 			}
 
 			for _, cred := range creds {
-				handlers.SyncRecras(&cred, entry.WithField("recras_hostname", cred.RecrasHostname), app.db)
+				if ctx.Err() != nil {
+					return
+				}
+				handlers.SyncRecras(ctx, &cred, entry.WithField("recras_hostname", cred.RecrasHostname), app.db)
 			}
 		}
 
 		syncAll(logrus.WithField("timed_sync", time.Now()))
 
-		for t := range ticker {
+		for {
+			var t time.Time
+			select {
+			case t = <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
 			entry := logrus.WithField("timed_sync", t)
 			if t.After(time.Now().Add(time.Hour)) {
 				entry.Warnf("It is already %s, skipping sync", time.Now())
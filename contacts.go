@@ -0,0 +1,89 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+const contactURI = "/api/v1/%d/crm/Contacts"
+
+// Contact is a person working at an Account
+type Contact struct {
+	ID                  string `json:",omitempty"`
+	Account             string
+	FirstName           string `json:",omitempty"`
+	LastName            string
+	Email               string `json:",omitempty"`
+	Phone               string `json:",omitempty"`
+	JobTitleDescription string `json:",omitempty"`
+	IsMainContact       bool   `json:",omitempty"`
+}
+
+type contacts struct {
+	D struct {
+		Results []Contact `json:"results"`
+	} `json:"d"`
+}
+
+var (
+	ErrContactAccountRequired  = errors.New("Field `Account` on type `Contact` is mandatory")
+	ErrContactLastNameRequired = errors.New("Field `LastName` on type `Contact` is mandatory")
+)
+
+func (c *Client) FindContactsByAccount(ctx context.Context, account string) ([]Contact, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Contact{}
+	u := NewQuery().Filter(Eq("Account", GUID(account))).URL(fmt.Sprintf(contactURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (ct *Contact) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if ct.Account == "" {
+		return ErrContactAccountRequired
+	}
+	if ct.LastName == "" {
+		return ErrContactLastNameRequired
+	}
+
+	bs, err := json.Marshal(ct)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(contactURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]Contact{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*ct = envelope["d"]
+	return nil
+}
+
+func (ct *Contact) Update(ctx context.Context, c *Client, orig Contact) error {
+	return c.update(ctx, contactURI, ct.ID, orig, *ct, "ID")
+}
+
+func (ct *Contact) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, contactURI, ct.ID)
+}
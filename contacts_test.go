@@ -0,0 +1,58 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestContact(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/crm/Contacts" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		if r.Method == "GET" {
+			if f := r.URL.Query().Get("$filter"); f != "Account eq guid'account-guid'" {
+				t.Errorf("Unexpected $filter %#v", f)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"contact-guid","Account":"account-guid","LastName":"Jansen"}]}}`)
+			return
+		}
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		payload["ID"] = "contact-guid"
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(map[string]interface{}{"d": payload})
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	ct := Contact{LastName: "Jansen"}
+	if err := ct.Save(context.Background(), cl); err != ErrContactAccountRequired {
+		t.Errorf("Expected ErrContactAccountRequired, got %#v", err)
+	}
+	ct.Account = "account-guid"
+	if err := ct.Save(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if ct.ID != "contact-guid" || ct.LastName != "Jansen" {
+		t.Errorf("Expected contact to be saved, got %#v", ct)
+	}
+
+	cts, err := cl.FindContactsByAccount(context.Background(), "account-guid")
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if len(cts) != 1 || cts[0].ID != "contact-guid" {
+		t.Errorf("Expected 1 contact, got %#v", cts)
+	}
+}
@@ -0,0 +1,130 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+const (
+	costCenterURI = "/api/v1/%d/hrm/Costcenters"
+	costUnitURI   = "/api/v1/%d/hrm/Costunits"
+)
+
+// CostCenter splits revenue and costs by department or venue. Lines refer to
+// it by Code.
+type CostCenter struct {
+	ID          string `json:",omitempty"`
+	Code        string
+	Description string
+	Active      bool `json:",omitempty"`
+}
+
+// CostUnit splits revenue and costs by product or activity type. Lines refer
+// to it by Code.
+type CostUnit struct {
+	ID          string `json:",omitempty"`
+	Code        string
+	Description string
+}
+
+func (c *Client) GetCostCenters(ctx context.Context) ([]CostCenter, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []CostCenter{}
+	u := NewQuery().OrderBy("Code").URL(fmt.Sprintf(costCenterURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) GetCostUnits(ctx context.Context) ([]CostUnit, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []CostUnit{}
+	u := NewQuery().OrderBy("Code").URL(fmt.Sprintf(costUnitURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var (
+	ErrCostCenterCodeRequired        = errors.New("Field `Code` on type `CostCenter` is mandatory")
+	ErrCostCenterDescriptionRequired = errors.New("Field `Description` on type `CostCenter` is mandatory")
+	ErrCostUnitCodeRequired          = errors.New("Field `Code` on type `CostUnit` is mandatory")
+	ErrCostUnitDescriptionRequired   = errors.New("Field `Description` on type `CostUnit` is mandatory")
+)
+
+func (cc *CostCenter) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if cc.Code == "" {
+		return ErrCostCenterCodeRequired
+	}
+	if cc.Description == "" {
+		return ErrCostCenterDescriptionRequired
+	}
+
+	bs, err := json.Marshal(cc)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(costCenterURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]CostCenter{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*cc = envelope["d"]
+	return nil
+}
+
+func (cu *CostUnit) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if cu.Code == "" {
+		return ErrCostUnitCodeRequired
+	}
+	if cu.Description == "" {
+		return ErrCostUnitDescriptionRequired
+	}
+
+	bs, err := json.Marshal(cu)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(costUnitURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]CostUnit{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*cu = envelope["d"]
+	return nil
+}
@@ -0,0 +1,67 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGetCostCenters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/hrm/Costcenters":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"cc-guid","Code":"100","Description":"Strand","Active":true}]}}`)
+		case "/api/v1/123/hrm/Costunits":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"cu-guid","Code":"KANO","Description":"Kanoën"}]}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	centers, err := cl.GetCostCenters(context.Background())
+	if err != nil || len(centers) != 1 || centers[0] != (CostCenter{ID: "cc-guid", Code: "100", Description: "Strand", Active: true}) {
+		t.Errorf("Unexpected cost centers %#v, %#v", centers, err)
+	}
+	units, err := cl.GetCostUnits(context.Background())
+	if err != nil || len(units) != 1 || units[0].Code != "KANO" {
+		t.Errorf("Unexpected cost units %#v, %#v", units, err)
+	}
+}
+
+func TestSaveCostCenter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/123/hrm/Costcenters" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		payload["ID"] = "cc-guid"
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(map[string]interface{}{"d": payload})
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	cc := CostCenter{Code: "100"}
+	if err := cc.Save(context.Background(), cl); err != ErrCostCenterDescriptionRequired {
+		t.Errorf("Expected ErrCostCenterDescriptionRequired, got %#v", err)
+	}
+	cc.Description = "Strand"
+	if err := cc.Save(context.Background(), cl); err != nil || cc.ID != "cc-guid" {
+		t.Errorf("Expected the cost center to be saved, got %#v, %#v", cc, err)
+	}
+}
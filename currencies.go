@@ -0,0 +1,79 @@
+package exactonline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const (
+	currencyURI     = "/api/v1/%d/general/Currencies"
+	exchangeRateURI = "/api/v1/%d/financial/ExchangeRates"
+)
+
+type Currency struct {
+	Code            string
+	Description     string
+	AmountPrecision float64 `json:",omitempty"`
+}
+
+func (c *Client) GetCurrencies(ctx context.Context) ([]Currency, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Currency{}
+	u := NewQuery().OrderBy("Code").URL(fmt.Sprintf(currencyURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExchangeRate is the Rate to convert SourceCurrency into TargetCurrency
+// with, from StartDate until the next rate of the pair
+type ExchangeRate struct {
+	ID             string `json:",omitempty"`
+	SourceCurrency string
+	TargetCurrency string
+	Rate           float64
+	StartDate      odata2json.Date
+}
+
+// GetExchangeRates returns the rates matching f, newest first
+func (c *Client) GetExchangeRates(ctx context.Context, f Filter) ([]ExchangeRate, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []ExchangeRate{}
+	u := NewQuery().Filter(f).OrderByDesc("StartDate").URL(fmt.Sprintf(exchangeRateURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var ErrExchangeRateNotFound = errors.New("No exchange rate for date")
+
+// FindExchangeRate returns the rate from source into target on date
+func (c *Client) FindExchangeRate(ctx context.Context, source, target string, date time.Time) (ExchangeRate, error) {
+	if c.division == 0 {
+		return ExchangeRate{}, ErrNoDivision
+	}
+	out := []ExchangeRate{}
+	f := And(
+		Eq("SourceCurrency", source),
+		Eq("TargetCurrency", target),
+		Lt("StartDate", date.AddDate(0, 0, 1)),
+	)
+	u := NewQuery().Filter(f).OrderByDesc("StartDate").Top(1).URL(fmt.Sprintf(exchangeRateURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return ExchangeRate{}, err
+	}
+	if len(out) == 0 {
+		return ExchangeRate{}, ErrExchangeRateNotFound
+	}
+	return out[0], nil
+}
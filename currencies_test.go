@@ -0,0 +1,60 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGetCurrencies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/general/Currencies" {
+			t.Errorf("Expected path to be `/api/v1/123/general/Currencies`, got `%s`", p)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"Code":"EUR","Description":"Euro","AmountPrecision":0.01},{"Code":"GBP","Description":"Brits pond","AmountPrecision":0.01}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	currencies, err := cl.GetCurrencies(context.Background())
+	if err != nil || len(currencies) != 2 || currencies[1].Code != "GBP" {
+		t.Errorf("Unexpected currencies %#v, %#v", currencies, err)
+	}
+}
+
+func TestFindExchangeRate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/financial/ExchangeRates" {
+			t.Errorf("Expected path to be `/api/v1/123/financial/ExchangeRates`, got `%s`", p)
+		}
+		q := r.URL.Query()
+		if f := q.Get("$filter"); f != "(SourceCurrency eq 'GBP') and (TargetCurrency eq 'EUR') and (StartDate lt datetime'2015-01-03T00:00:00')" {
+			t.Errorf("Unexpected $filter %#v", f)
+		}
+		if q.Get("$orderby") != "StartDate desc" || q.Get("$top") != "1" {
+			t.Errorf("Expected the newest rate to be requested, got %#v", q)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"guid","SourceCurrency":"GBP","TargetCurrency":"EUR","Rate":1.25,"StartDate":"/Date(1420070400000)/"}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	r, err := cl.FindExchangeRate(context.Background(), "GBP", "EUR", time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if r.Rate != 1.25 || r.StartDate.Time != time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Unexpected rate %#v", r)
+	}
+}
@@ -0,0 +1,46 @@
+package dal
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// CostRule books invoice lines on a cost center and cost unit, see
+// synctool.CostRule. Zero IDs match any product, productgroep or locatie.
+type CostRule struct {
+	ID             int64  `db:"id"`
+	RecrasHostname string `db:"recras_hostname"`
+	ProductID      int    `db:"product_id"`
+	ProductgroepID int    `db:"productgroep_id"`
+	LocatieID      int    `db:"locatie_id"`
+	CostCenter     string `db:"cost_center"`
+	CostUnit       string `db:"cost_unit"`
+}
+
+// CostRules returns the cost rules of c in the order they were added, which
+// is the order they are tried in
+func (c *Credential) CostRules(db *sqlx.DB) ([]CostRule, error) {
+	rules := []CostRule{}
+	err := db.Select(&rules, `SELECT * FROM cost_rule WHERE recras_hostname=$1 ORDER BY id`, c.RecrasHostname)
+	if err != nil {
+		return nil, CredentialError{"costRules", err}
+	}
+	return rules, nil
+}
+
+func (c *Credential) AddCostRule(db *sqlx.DB, r CostRule) error {
+	r.RecrasHostname = c.RecrasHostname
+	_, err := db.NamedExec(`INSERT INTO cost_rule (recras_hostname, product_id, productgroep_id, locatie_id, cost_center, cost_unit)
+		VALUES (:recras_hostname, :product_id, :productgroep_id, :locatie_id, :cost_center, :cost_unit)`, r)
+	if err != nil {
+		return CredentialError{"addCostRule", err}
+	}
+	return nil
+}
+
+func (c *Credential) DeleteCostRule(db *sqlx.DB, id int64) error {
+	_, err := db.Exec(`DELETE FROM cost_rule WHERE recras_hostname=$1 AND id=$2`, c.RecrasHostname, id)
+	if err != nil {
+		return CredentialError{"deleteCostRule", err}
+	}
+	return nil
+}
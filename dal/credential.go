@@ -18,6 +18,19 @@ type Credential struct {
 
 	State     string    `db:"state"`
 	StartSync time.Time `db:"start_sync_date"`
+
+	// BookingMode tells whether invoices are synced as sales entries or as
+	// sales invoices, see synctool.BookingMode
+	BookingMode string `db:"booking_mode"`
+
+	// BankJournal and CashJournal are the codes of the Exact Online journals
+	// Recras payments are booked in; payments are not synced without them
+	BankJournal string `db:"bank_journal"`
+	CashJournal string `db:"cash_journal"`
+
+	// ClosedPeriodPolicy tells what happens to invoices dated in a closed
+	// financial period, see synctool.ClosedPeriodPolicy
+	ClosedPeriodPolicy string `db:"closed_period_policy"`
 }
 
 func FindAllCredentials(db *sqlx.DB) ([]Credential, error) {
@@ -94,3 +107,46 @@ func (c *Credential) UpdateToken(db *sqlx.DB, accessToken, refreshToken string)
 	}
 	return nil
 }
+
+func (c *Credential) UpdateBookingMode(db *sqlx.DB, mode string) error {
+	c.BookingMode = mode
+
+	stmt, err := db.PrepareNamed(`UPDATE credential SET booking_mode=:booking_mode WHERE recras_hostname=:recras_hostname`)
+	if err != nil {
+		return CredentialError{"prepareUpdateBookingMode", err}
+	}
+	_, err = stmt.Exec(c)
+	if err != nil {
+		return CredentialError{"updateBookingMode", err}
+	}
+	return nil
+}
+
+func (c *Credential) UpdateClosedPeriodPolicy(db *sqlx.DB, policy string) error {
+	c.ClosedPeriodPolicy = policy
+
+	stmt, err := db.PrepareNamed(`UPDATE credential SET closed_period_policy=:closed_period_policy WHERE recras_hostname=:recras_hostname`)
+	if err != nil {
+		return CredentialError{"prepareUpdateClosedPeriodPolicy", err}
+	}
+	_, err = stmt.Exec(c)
+	if err != nil {
+		return CredentialError{"updateClosedPeriodPolicy", err}
+	}
+	return nil
+}
+
+func (c *Credential) UpdatePaymentJournals(db *sqlx.DB, bankJournal, cashJournal string) error {
+	c.BankJournal = bankJournal
+	c.CashJournal = cashJournal
+
+	stmt, err := db.PrepareNamed(`UPDATE credential SET bank_journal=:bank_journal, cash_journal=:cash_journal WHERE recras_hostname=:recras_hostname`)
+	if err != nil {
+		return CredentialError{"prepareUpdatePaymentJournals", err}
+	}
+	_, err = stmt.Exec(c)
+	if err != nil {
+		return CredentialError{"updatePaymentJournals", err}
+	}
+	return nil
+}
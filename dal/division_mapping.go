@@ -0,0 +1,46 @@
+package dal
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// DivisionMapping ties a Recras bedrijf to the Exact Online division it is
+// synced to, instead of the division found by its VAT number
+type DivisionMapping struct {
+	RecrasHostname string `db:"recras_hostname"`
+	BedrijfID      int    `db:"bedrijf_id"`
+	Division       int    `db:"division"`
+}
+
+// DivisionMappings returns the divisions of the mapped bedrijven of c by
+// bedrijf ID
+func (c *Credential) DivisionMappings(db *sqlx.DB) (map[int]int, error) {
+	mappings := []DivisionMapping{}
+	err := db.Select(&mappings, `SELECT * FROM division_mapping WHERE recras_hostname=$1`, c.RecrasHostname)
+	if err != nil {
+		return nil, CredentialError{"divisionMappings", err}
+	}
+	out := make(map[int]int, len(mappings))
+	for _, m := range mappings {
+		out[m.BedrijfID] = m.Division
+	}
+	return out, nil
+}
+
+// UpdateDivisionMapping maps bedrijf bedrijfID to division, or removes its
+// mapping when division is 0
+func (c *Credential) UpdateDivisionMapping(db *sqlx.DB, bedrijfID, division int) error {
+	if division == 0 {
+		_, err := db.Exec(`DELETE FROM division_mapping WHERE recras_hostname=$1 AND bedrijf_id=$2`, c.RecrasHostname, bedrijfID)
+		if err != nil {
+			return CredentialError{"deleteDivisionMapping", err}
+		}
+		return nil
+	}
+	_, err := db.NamedExec(`INSERT INTO division_mapping (recras_hostname, bedrijf_id, division) VALUES (:recras_hostname, :bedrijf_id, :division)
+		ON CONFLICT (recras_hostname, bedrijf_id) DO UPDATE SET division=EXCLUDED.division`, DivisionMapping{c.RecrasHostname, bedrijfID, division})
+	if err != nil {
+		return CredentialError{"updateDivisionMapping", err}
+	}
+	return nil
+}
@@ -1,10 +1,10 @@
 package exactonline
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/Recras/exactonline/httperror"
@@ -21,14 +21,15 @@ type me struct {
 	} `json:"d"`
 }
 
-// GetDefaultDivision retrieves and sets the default division on Client c
-func (c *Client) GetDefaultDivision() error {
-	resp, err := c.Client.Get("/api/v1/current/Me")
+// DefaultDivision returns a handle for the current division of the user,
+// see WithDivision. c itself is left unchanged.
+func (c *Client) DefaultDivision(ctx context.Context) (*Client, error) {
+	resp, err := c.get(ctx, "/api/v1/current/Me")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if resp.StatusCode != 200 {
-		return httperror.New(resp)
+		return nil, httperror.New(resp)
 	}
 	defer resp.Body.Close()
 
@@ -37,19 +38,32 @@ func (c *Client) GetDefaultDivision() error {
 	dec := json.NewDecoder(resp.Body)
 	err = dec.Decode(out)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.Division = out.D.Results[0].CurrentDivision
+	return c.WithDivision(out.D.Results[0].CurrentDivision), nil
+}
+
+// GetDefaultDivision retrieves and sets the default division on Client c.
+//
+// Deprecated: it changes c for everyone sharing it, use DefaultDivision.
+func (c *Client) GetDefaultDivision(ctx context.Context) error {
+	dc, err := c.DefaultDivision(ctx)
+	if err != nil {
+		return err
+	}
+	c.division = dc.division
 	return nil
 }
 
 type Division struct {
-	Code      int
-	HID       int64 `json:",string"`
-	VATNumber string
-	Main      bool
-	Country   string
+	Code        int
+	HID         int64 `json:",string"`
+	Description string
+	VATNumber   string
+	Main        bool
+	Country     string
+	Currency    string
 }
 type divisions struct {
 	D struct {
@@ -59,77 +73,61 @@ type divisions struct {
 
 var ErrDivisionNotFound = errors.New("exactonline/api: No division found by VAT number")
 
-func (c *Client) findDivisionByVATNumber(vn string, divisionID int) (Division, error) {
-	vnq := url.QueryEscape("VATNumber eq '" + vn + "'")
-
-	resp, err := c.Client.Get(fmt.Sprintf("/api/v1/%d/hrm/Divisions?$filter=%s", divisionID, vnq))
+// DivisionByVATNumber returns a handle for the division with VAT number vn,
+// see WithDivision and MatchDivisionByVATNumber. c itself is left unchanged.
+// To look up several VAT numbers, call ListDivisions once and match on its
+// result instead.
+func (c *Client) DivisionByVATNumber(ctx context.Context, vn string) (*Client, error) {
+	divisions, err := c.ListDivisions(ctx)
 	if err != nil {
-		return Division{}, err
+		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return Division{}, httperror.New(resp)
-	}
-
-	out := &divisions{}
-
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(out)
+	div, err := MatchDivisionByVATNumber(divisions, vn)
 	if err != nil {
-		return Division{}, err
-	}
-
-	if len(out.D.Results) == 0 {
-		return Division{}, ErrDivisionNotFound
+		return nil, err
 	}
-
-	return out.D.Results[0], nil
+	return c.WithDivision(div.Code), nil
 }
 
-func (c *Client) SetDivisionByVATNumber(vn string) error {
-	if c.Division == 0 {
-		return ErrNoDivision
-	}
-
-	vn = strings.Replace(vn, ".", "", -1)
-
-	div, err := c.findDivisionByVATNumber(vn, c.Division)
-	if err != nil && err != ErrDivisionNotFound {
-		return err
-	} else if err == nil {
-		c.Division = div.Code
-		return nil
-	}
-
-	div, err = c.findSystemDivisionByVATNumber(vn)
+// SetDivisionByVATNumber sets the division of c to the division with VAT
+// number vn.
+//
+// Deprecated: it changes c for everyone sharing it, use DivisionByVATNumber.
+func (c *Client) SetDivisionByVATNumber(ctx context.Context, vn string) error {
+	dc, err := c.DivisionByVATNumber(ctx, vn)
 	if err != nil {
 		return err
 	}
-	c.Division = div.Code
+	c.division = dc.division
 	return nil
 }
 
-func (c *Client) findSystemDivisionByVATNumber(vn string) (Division, error) {
-	resp, err := c.Client.Get(fmt.Sprintf("/api/v1/%d/system/Divisions", c.Division))
-	if err != nil {
-		return Division{}, err
+// ListDivisions returns the divisions the user has access to, ordered by Code
+func (c *Client) ListDivisions(ctx context.Context) ([]Division, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
 	}
-	if resp.StatusCode != 200 {
-		return Division{}, httperror.New(resp)
+	out := []Division{}
+	u := NewQuery().OrderBy("Code").URL(fmt.Sprintf("/api/v1/%d/system/Divisions", c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
 	}
+	return out, nil
+}
 
-	out := &divisions{}
+func normalizeVATNumber(vn string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "", " ", "", "-", "").Replace(vn))
+}
 
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(out)
-	if err != nil {
-		return Division{}, err
+// MatchDivisionByVATNumber returns the division in divisions with VAT number
+// vn, ignoring case, dots, spaces and dashes
+func MatchDivisionByVATNumber(divisions []Division, vn string) (Division, error) {
+	vn = normalizeVATNumber(vn)
+	if vn == "" {
+		return Division{}, ErrDivisionNotFound
 	}
-
-	for _, div := range out.D.Results {
-		d, err := c.findDivisionByVATNumber(vn, div.Code)
-		if err != nil && err != ErrDivisionNotFound {
-			return Division{}, err
-		} else if err == nil {
+	for _, d := range divisions {
+		if normalizeVATNumber(d.VATNumber) == vn {
 			return d, nil
 		}
 	}
@@ -1,16 +1,18 @@
 package exactonline
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"golang.org/x/oauth2"
 )
 
-func TestGetDefaultDivision(t *testing.T) {
+func TestDefaultDivision(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/current/Me" {
 			t.Errorf("Expected URL path to be `/api/v1/current/Me`, got `%s`", r.URL.Path)
@@ -24,137 +26,155 @@ func TestGetDefaultDivision(t *testing.T) {
 	defer ts.Close()
 
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(3 * time.Second)})
-	err := cl.GetDefaultDivision()
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	dc, err := cl.DefaultDivision(context.Background())
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
-	} else if cl.Division != 1234 {
-		t.Errorf("Expected currentDivision to be 1234, got %d", cl.Division)
+	} else if dc.Division() != 1234 || cl.Division() != 0 {
+		t.Errorf("Expected currentDivision to be 1234 and c to be unchanged, got %d and %d", dc.Division(), cl.Division())
 	}
 }
 
 func TestSetDivisionWithoutDivision(t *testing.T) {
 	c := Config{}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(3 * time.Second)})
-	err := cl.SetDivisionByVATNumber("")
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	err := cl.SetDivisionByVATNumber(context.Background(), "")
 	if err != ErrNoDivision {
 		t.Errorf("Expected error to be ErrNoDivision")
 	}
 }
 
-func TestSetDivisionCredentials(t *testing.T) {
+func TestSetDivisionByVATNumber(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("Authorization") != "Bearer valid" {
 			http.Error(w, "Unauthorized", 401)
 			return
 		}
-		if r.URL.Path != "/api/v1/1234/hrm/Divisions" {
-			t.Errorf("Expected URL Path to be `/api/v1/1234/hrm/Divisions`, got %#v", r.URL.Path)
+		if r.URL.Path != "/api/v1/1234/system/Divisions" {
+			t.Errorf("Expected URL Path to be `/api/v1/1234/system/Divisions`, got %#v", r.URL.Path)
 		}
-		if vn := r.URL.Query()["$filter"]; vn[0] != "VATNumber eq 'NL123456789B01'" {
-			t.Errorf("Expected $filter query parameter to be VAT number, got %#v", vn)
-		}
-
-		fmt.Fprintf(w, `{"d":{"results":[{"Code": 456, "HID": "789", "VATNumber": "NL123456789B01"}]}}`)
+		fmt.Fprintf(w, `{"d":{"results":[{"Code": 1234, "VATNumber": "NL987654321B01"}, {"Code": 456, "HID": "789", "VATNumber": "NL123456789B01"}]}}`)
 	}))
 	defer ts.Close()
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
 		AccessToken: "invalid",
-		Expiry:      time.Now().Add(3 * time.Second),
+		Expiry:      time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	err := cl.SetDivisionByVATNumber("NL123456789B01")
-
-	if err == nil {
+	cl.division = 1234
+	if err := cl.SetDivisionByVATNumber(context.Background(), "NL123456789B01"); err == nil {
 		t.Errorf("Expected error, got nil")
 	}
 
 	cl = c.NewClient(oauth2.Token{
 		AccessToken: "valid",
-		Expiry:      time.Now().Add(1 * time.Second),
+		Expiry:      time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-
-	err = cl.SetDivisionByVATNumber("NL123456789B01")
-	if err != nil {
+	cl.division = 1234
+	if err := cl.SetDivisionByVATNumber(context.Background(), "NL12.3456.789.B01"); err != nil {
 		t.Errorf("expected no error, got %#v", err)
 	}
-	if cl.Division != 456 {
-		t.Errorf("expected division with code 456, got %#v", cl.Division)
+	if cl.division != 456 {
+		t.Errorf("expected division with code 456, got %#v", cl.division)
+	}
+	cl.division = 1234
+	if err := cl.SetDivisionByVATNumber(context.Background(), "NL000000000B01"); err != ErrDivisionNotFound {
+		t.Errorf("Expected ErrDivisionNotFound, got %#v", err)
 	}
 }
 
-func TestSetDivisionPeriods(t *testing.T) {
+func TestDivisionByVATNumber(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]bool{}
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if vn := r.URL.Query()["$filter"]; vn[0] != "VATNumber eq 'NL123456789B01'" {
-			t.Errorf("Expected $filter query parameter to to have stripped periods, got %#v", vn)
+		mu.Lock()
+		seen[r.URL.Path] = true
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v1/1234/system/Divisions":
+			fmt.Fprint(w, `{"d":{"results":[{"Code": 1, "VATNumber": "NL1B01"}, {"Code": 2, "VATNumber": "NL2B01"}]}}`)
+		case "/api/v1/1/logistics/Items", "/api/v1/2/logistics/Items":
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
 		}
-
-		fmt.Fprintf(w, `{"d":{"results":[{"Code": 456, "HID": "789", "VATNumber": "NL123456789B01"}]}}`)
 	}))
 	defer ts.Close()
 
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
-	})
-	cl.Division = 1234
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+
+	var wg sync.WaitGroup
+	for _, vn := range []string{"NL1B01", "NL2B01"} {
+		wg.Add(1)
+		go func(vn string) {
+			defer wg.Done()
+			dc, err := cl.DivisionByVATNumber(context.Background(), vn)
+			if err != nil {
+				t.Errorf("Expected no error, got %#v", err)
+				return
+			}
+			if _, err := dc.GetAllItems(context.Background()); err != nil {
+				t.Errorf("Expected no error, got %#v", err)
+			}
+		}(vn)
+	}
+	wg.Wait()
+
+	if cl.division != 1234 {
+		t.Errorf("Expected the original client to keep division 1234, got %d", cl.division)
+	}
+	if !seen["/api/v1/1/logistics/Items"] || !seen["/api/v1/2/logistics/Items"] {
+		t.Errorf("Expected items to be retrieved from both divisions, got %v", seen)
+	}
+}
 
-	cl.SetDivisionByVATNumber("NL12.3456.789.B01")
+func TestWithDivision(t *testing.T) {
+	c := Config{}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1
+	dc := cl.WithDivision(2)
+	if cl.division != 1 || dc.Division() != 2 {
+		t.Errorf("Expected divisions 1 and 2, got %d and %d", cl.division, dc.Division())
+	}
+	if dc.TokenSource != cl.TokenSource {
+		t.Errorf("Expected the token source to be shared")
+	}
 }
 
-func TestSetDivisionSystemDivisionsSingleResult(t *testing.T) {
-	systemDivisionsCalled := false
-	division1235Called := false
-	division1236Called := false
+func TestListDivisions(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/v1/1234/hrm/Divisions" {
-			fmt.Fprintf(w, `{"d":{"results":[]}}`)
-			return
-		}
-		if r.URL.Path == "/api/v1/1234/system/Divisions" {
-			systemDivisionsCalled = true
-			fmt.Fprintf(w, `{"d":{"results":[{"Code":1236},{"Code": 1235}]}}`)
-			return
+		if r.URL.Path != "/api/v1/1234/system/Divisions" {
+			t.Errorf("Expected URL Path to be `/api/v1/1234/system/Divisions`, got %#v", r.URL.Path)
 		}
-		if r.URL.Path == "/api/v1/1236/hrm/Divisions" {
-			division1236Called = true
-			fmt.Fprintf(w, `{"d":{"results":[]}}`)
-			return
-		}
-		if r.URL.Path == "/api/v1/1235/hrm/Divisions" {
-			division1235Called = true
-			if vn := r.URL.Query()["$filter"]; vn[0] != "VATNumber eq 'NL123456789B01'" {
-				t.Errorf("Expected $filter query parameter to be VAT number, got %#v", vn)
-			}
-			fmt.Fprintf(w, `{"d":{"results":[{"Code": 456, "HID": "789", "VATNumber": "NL123456789B01"}]}}`)
-			return
+		if o := r.URL.Query().Get("$orderby"); o != "Code" {
+			t.Errorf("Expected divisions to be ordered by Code, got %#v", o)
 		}
+		fmt.Fprint(w, `{"d":{"results":[
+			{"Code": 1234, "Description": "Hoofdkantoor", "VATNumber": "NL123456789B01", "Country": "NL"},
+			{"Code": 5678, "Description": "Vestiging", "VATNumber": "", "Country": "BE"}
+		]}}`)
 	}))
 	defer ts.Close()
 
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{
-		AccessToken: "valid",
-		Expiry:      time.Now().Add(3 * time.Second),
-	})
-	cl.Division = 1234
-	err := cl.SetDivisionByVATNumber("NL123456789B01")
-	if !systemDivisionsCalled {
-		t.Errorf("Expected SetDivisionByVATNumber to call system/Divisions API when hrm/Division does have results")
-	}
-	if !division1235Called {
-		t.Errorf("Expected SetDivisionByVATNumber to call other hrm/Divisions API when hrm/Division does have results")
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	divs, err := cl.ListDivisions(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
 	}
-	if !division1236Called {
-		t.Errorf("Expected SetDivisionByVATNumber to call other hrm/Divisions API when hrm/Division does have results")
+	if len(divs) != 2 || divs[1].Description != "Vestiging" || divs[1].Country != "BE" {
+		t.Fatalf("Unexpected divisions %#v", divs)
 	}
-	if err != nil {
-		t.Errorf("Expected no error, got %#v", err)
+
+	d, err := MatchDivisionByVATNumber(divs, "nl 1234.56789 b01")
+	if err != nil || d.Code != 1234 {
+		t.Errorf("Expected division 1234, got %#v, %#v", d, err)
 	}
-	if cl.Division != 456 {
-		t.Errorf("Expected Division with code 456, got %#v", cl.Division)
+	if _, err := MatchDivisionByVATNumber(divs, ""); err != ErrDivisionNotFound {
+		t.Errorf("Expected an empty VAT number not to match, got %#v", err)
 	}
 }
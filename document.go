@@ -2,10 +2,11 @@ package exactonline
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
+	"io/ioutil"
 
 	"github.com/Recras/exactonline/httperror"
 	"github.com/Recras/exactonline/odata2json"
@@ -16,6 +17,10 @@ type Document struct {
 	Subject string
 	Type    int
 	Account string `json:",omitempty"`
+
+	// FinancialTransactionEntryID is the entry the document is attached to,
+	// set by Exact Online when a SalesEntry refers to the document
+	FinancialTransactionEntryID string `json:",omitempty"`
 }
 type documents struct {
 	D struct {
@@ -29,8 +34,8 @@ var ErrNoSubject = errors.New("Document has no Subject")
 var ErrNoType = errors.New("Document has no Type")
 var ErrNoAccount = errors.New("Document has no Account")
 
-func (d *Document) Save(cl *Client) error {
-	if cl.Division == 0 {
+func (d *Document) Save(ctx context.Context, cl *Client) error {
+	if cl.division == 0 {
 		return ErrNoDivision
 	}
 	if d.Subject == "" {
@@ -49,8 +54,8 @@ func (d *Document) Save(cl *Client) error {
 	}
 	bb := bytes.NewBuffer(bs)
 
-	u := fmt.Sprintf(documentURI, cl.Division)
-	resp, err := cl.Client.Post(u, "application/json", bb)
+	u := fmt.Sprintf(documentURI, cl.division)
+	resp, err := cl.post(ctx, u, bb)
 	if err != nil {
 		return err
 	}
@@ -70,11 +75,44 @@ func (d *Document) Save(cl *Client) error {
 	return nil
 }
 
+// GetDocuments returns the documents matching f
+func (c *Client) GetDocuments(ctx context.Context, f Filter) ([]Document, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Document{}
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(documentURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) FindDocumentsByAccount(ctx context.Context, accountID string) ([]Document, error) {
+	return c.GetDocuments(ctx, Eq("Account", GUID(accountID)))
+}
+
+func (c *Client) FindDocumentsBySalesEntry(ctx context.Context, entryID string) ([]Document, error) {
+	return c.GetDocuments(ctx, Eq("FinancialTransactionEntryID", GUID(entryID)))
+}
+
+func (d *Document) Update(ctx context.Context, c *Client, orig Document) error {
+	return c.update(ctx, documentURI, d.ID, orig, *d, "ID")
+}
+
+func (d *Document) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, documentURI, d.ID)
+}
+
+// DocumentAttachment is a file of a Document. When listing attachments Exact
+// Online leaves Attachment empty and sets URL, use Download for the content.
 type DocumentAttachment struct {
 	ID         string `json:",omitempty"`
 	Attachment odata2json.Binary
 	Document   string
 	FileName   string
+	FileSize   float64 `json:",omitempty"`
+	URL        string  `json:"Url,omitempty"`
 }
 type documentattachments struct {
 	D struct {
@@ -88,8 +126,8 @@ var ErrNoFileName = errors.New("DocumentAttachment has no FileName")
 var ErrNoDocument = errors.New("DocumentAttachment has no Document")
 var ErrNoAttachment = errors.New("DocumentAttachment has no Attachment")
 
-func (d *DocumentAttachment) Save(cl *Client) error {
-	if cl.Division == 0 {
+func (d *DocumentAttachment) Save(ctx context.Context, cl *Client) error {
+	if cl.division == 0 {
 		return ErrNoDivision
 	}
 
@@ -109,8 +147,8 @@ func (d *DocumentAttachment) Save(cl *Client) error {
 	}
 	bb := bytes.NewBuffer(bs)
 
-	u := fmt.Sprintf(documentAttachmentURI, cl.Division)
-	resp, err := cl.Client.Post(u, "application/json", bb)
+	u := fmt.Sprintf(documentAttachmentURI, cl.division)
+	resp, err := cl.post(ctx, u, bb)
 	if err != nil {
 		return err
 	}
@@ -130,6 +168,44 @@ func (d *DocumentAttachment) Save(cl *Client) error {
 	return nil
 }
 
+func (c *Client) GetDocumentAttachments(ctx context.Context, documentID string) ([]DocumentAttachment, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []DocumentAttachment{}
+	u := NewQuery().Filter(Eq("Document", GUID(documentID))).URL(fmt.Sprintf(documentAttachmentURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var ErrNoAttachmentContent = errors.New("DocumentAttachment has neither Attachment nor Url")
+
+// Download returns the content of the attachment, from Attachment when it
+// was returned inline and from URL otherwise
+func (d *DocumentAttachment) Download(ctx context.Context, c *Client) ([]byte, error) {
+	if len(d.Attachment) > 0 {
+		return d.Attachment, nil
+	}
+	if d.URL == "" {
+		return nil, ErrNoAttachmentContent
+	}
+	resp, err := c.get(ctx, d.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, httperror.New(resp)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (d *DocumentAttachment) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, documentAttachmentURI, d.ID)
+}
+
 type DocumentType struct {
 	ID          int
 	Description string
@@ -148,14 +224,12 @@ func (e ErrDocumentTypeNotFound) Error() string {
 	return fmt.Sprintf("DocumentType not found")
 }
 
-func (c *Client) FindDocumentTypeByDescription(d string) (DocumentType, error) {
-	if c.Division == 0 {
+func (c *Client) FindDocumentTypeByDescription(ctx context.Context, d string) (DocumentType, error) {
+	if c.division == 0 {
 		return DocumentType{}, ErrNoDivision
 	}
-	u := fmt.Sprintf(documentTypeURI, c.Division)
-	filt := url.Values{}
-	filt.Set("$filter", fmt.Sprintf("Description eq '%s'", d))
-	resp, err := c.Client.Get(fmt.Sprintf("%s?%s", u, filt.Encode()))
+	u := fmt.Sprintf(documentTypeURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(Eq("Description", d)).URL(u))
 	if err != nil {
 		return DocumentType{}, err
 	}
@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,9 +27,9 @@ func TestSaveDocument_empty(t *testing.T) {
 		Type:    10,
 		Account: "account-guid",
 	}
-	cl := &Client{Division: 123}
+	cl := &Client{division: 123}
 
-	err := d.Save(cl)
+	err := d.Save(context.Background(), cl)
 	if err != ErrNoSubject {
 		t.Errorf("Expected ErrNoSubject, got %#v", err)
 	}
@@ -37,7 +38,7 @@ func TestSaveDocument_empty(t *testing.T) {
 		Subject: "asf",
 		Account: "account-guid",
 	}
-	err = d.Save(cl)
+	err = d.Save(context.Background(), cl)
 	if err != ErrNoType {
 		t.Errorf("Expected ErrNoType, got %#v", err)
 	}
@@ -46,7 +47,7 @@ func TestSaveDocument_empty(t *testing.T) {
 		Subject: "asf",
 		Type:    10,
 	}
-	err = d.Save(cl)
+	err = d.Save(context.Background(), cl)
 	if err != ErrNoAccount {
 		t.Errorf("Expected ErrNoAccount, got %#v", err)
 	}
@@ -97,9 +98,9 @@ func TestSaveDocument(t *testing.T) {
 		Account: "account-guid",
 	}
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Second)})
-	cl.Division = 1234
-	err := d.Save(cl)
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	err := d.Save(context.Background(), cl)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -124,9 +125,9 @@ func TestSaveDocument_HTTPError(t *testing.T) {
 		Account: "account-guid",
 	}
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Second)})
-	cl.Division = 1234
-	err := d.Save(cl)
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	err := d.Save(context.Background(), cl)
 	if _, ok := err.(httperror.HTTPError); !ok {
 		t.Errorf("Expected HTTPError, got %#v", err)
 	}
@@ -138,7 +139,7 @@ func TestSaveDocument_NoDivision(t *testing.T) {
 		Subject: "Invoice 1-2-3",
 	}
 	cl := Client{}
-	err := d.Save(&cl)
+	err := d.Save(context.Background(), &cl)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -158,9 +159,9 @@ func TestSaveDocumentAttachment_empty(t *testing.T) {
 		Attachment: []byte("hello"),
 		Document:   "document-guid",
 	}
-	cl := &Client{Division: 123}
+	cl := &Client{division: 123}
 
-	err := d.Save(cl)
+	err := d.Save(context.Background(), cl)
 	if err != ErrNoFileName {
 		t.Errorf("Expected ErrNoFileName, got %#v", err)
 	}
@@ -169,7 +170,7 @@ func TestSaveDocumentAttachment_empty(t *testing.T) {
 		Attachment: []byte("hello"),
 		FileName:   "hello.txt",
 	}
-	err = d.Save(cl)
+	err = d.Save(context.Background(), cl)
 	if err != ErrNoDocument {
 		t.Errorf("Expected ErrNoDocument, got %#v", err)
 	}
@@ -178,7 +179,7 @@ func TestSaveDocumentAttachment_empty(t *testing.T) {
 		Document: "document-guid",
 		FileName: "hello.txt",
 	}
-	err = d.Save(cl)
+	err = d.Save(context.Background(), cl)
 	if err != ErrNoAttachment {
 		t.Errorf("Expected ErrNoAttachment, got %#v", err)
 	}
@@ -229,9 +230,9 @@ func TestSaveDocumentAttachment(t *testing.T) {
 		FileName:   "hello.txt",
 	}
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Second)})
-	cl.Division = 1234
-	err := d.Save(cl)
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	err := d.Save(context.Background(), cl)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -256,9 +257,9 @@ func TestSaveDocumentAttachment_HTTPError(t *testing.T) {
 		Attachment: []byte("hello"),
 	}
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Second)})
-	cl.Division = 1234
-	err := d.Save(cl)
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	err := d.Save(context.Background(), cl)
 	if _, ok := err.(httperror.HTTPError); !ok {
 		t.Errorf("Expected HTTPError, got %#v", err)
 	}
@@ -267,7 +268,7 @@ func TestSaveDocumentAttachment_HTTPError(t *testing.T) {
 func TestSaveDocumentAttachment_NoDivision(t *testing.T) {
 	d := DocumentAttachment{}
 	cl := Client{}
-	err := d.Save(&cl)
+	err := d.Save(context.Background(), &cl)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -282,7 +283,7 @@ func TestDocumentType(t *testing.T) {
 
 func TestFindDocumentTypeByDescription_NoDivision(t *testing.T) {
 	cl := Client{}
-	_, err := cl.FindDocumentTypeByDescription("sadf")
+	_, err := cl.FindDocumentTypeByDescription(context.Background(), "sadf")
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -303,11 +304,11 @@ func TestFindDocumentTypeByDescription_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
+	cl.division = 1234
 
-	_, err := cl.FindDocumentTypeByDescription("asdf")
+	_, err := cl.FindDocumentTypeByDescription(context.Background(), "asdf")
 	if !apiCalled {
 		t.Errorf("Expected DocumentTypes API to be called")
 	}
@@ -323,10 +324,10 @@ func TestFindDocumentTypeByDescription_Found(t *testing.T) {
 	defer ts.Close()
 
 	c := Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Second)})
-	cl.Division = 1234
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
 
-	dt, err := cl.FindDocumentTypeByDescription("asdf")
+	dt, err := cl.FindDocumentTypeByDescription(context.Background(), "asdf")
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -334,3 +335,61 @@ func TestFindDocumentTypeByDescription_Found(t *testing.T) {
 		t.Errorf("Expected documentType.ID to be 12, got %#v", dt)
 	}
 }
+
+func TestFindDocumentsBySalesEntry(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/documents/Documents":
+			if f := r.URL.Query().Get("$filter"); f != "FinancialTransactionEntryID eq guid'entry-guid'" {
+				t.Errorf("Unexpected filter %#v", f)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"document-guid","Subject":"Recras factuur 1-2-3","Type":10,"Account":"account-guid","FinancialTransactionEntryID":"entry-guid"}]}}`)
+		case "/api/v1/123/documents/DocumentAttachments":
+			if f := r.URL.Query().Get("$filter"); f != "Document eq guid'document-guid'" {
+				t.Errorf("Unexpected filter %#v", f)
+			}
+			fmt.Fprint(w, `{"d":{"results":[
+				{"ID":"inline-guid","Attachment":"JVBERi0=","Document":"document-guid","FileName":"inline.pdf"},
+				{"ID":"url-guid","Attachment":null,"Document":"document-guid","FileName":"factuur.pdf","Url":"/docs/SysAttachment.aspx?ID=url-guid"}
+			]}}`)
+		case "/docs/SysAttachment.aspx":
+			w.Write([]byte("%PDF-1.4"))
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	docs, err := cl.FindDocumentsBySalesEntry(context.Background(), "entry-guid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != "document-guid" {
+		t.Fatalf("Expected 1 document, got %#v", docs)
+	}
+
+	atts, err := cl.GetDocumentAttachments(context.Background(), docs[0].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(atts) != 2 {
+		t.Fatalf("Expected 2 attachments, got %#v", atts)
+	}
+	for _, a := range atts {
+		content, err := a.Download(context.Background(), cl)
+		if err != nil {
+			t.Errorf("Expected no error, got %#v", err)
+		}
+		if string(content[:4]) != "%PDF" {
+			t.Errorf("Expected PDF content for %s, got %#v", a.FileName, string(content))
+		}
+	}
+
+	if _, err := (&DocumentAttachment{}).Download(context.Background(), cl); err != ErrNoAttachmentContent {
+		t.Errorf("Expected ErrNoAttachmentContent, got %#v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const (
+	bankEntriesURI    = "/api/v1/%d/financialtransaction/BankEntries"
+	bankEntryLinesURI = "/api/v1/%d/financialtransaction/BankEntryLines"
+	cashEntriesURI    = "/api/v1/%d/financialtransaction/CashEntries"
+	cashEntryLinesURI = "/api/v1/%d/financialtransaction/CashEntryLines"
+)
+
+// FinancialEntryLine is a line of a BankEntry or CashEntry. A payment
+// received from a customer has a negative AmountFC; setting OurRef to the
+// InvoiceNumber of a Receivable matches the payment to it.
+type FinancialEntryLine struct {
+	ID          string `json:",omitempty"`
+	EntryID     string `json:",omitempty"`
+	Account     string `json:",omitempty"`
+	AmountFC    float64
+	Date        odata2json.Date
+	Description string
+	GLAccount   string `json:",omitempty"`
+	OurRef      int    `json:",omitempty"`
+}
+
+type deferredFinancialEntryLines struct {
+	Deferred struct {
+		URI string `json:"uri"`
+	} `json:"__deferred"`
+	Lines []FinancialEntryLine `json:"results,omitempty"`
+}
+
+func (d deferredFinancialEntryLines) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Lines)
+}
+
+// BankEntry books money received or paid through the bank journal
+// JournalCode
+type BankEntry struct {
+	ID            string `json:"EntryID,omitempty"`
+	JournalCode   string
+	EntryNumber   int                         `json:",omitempty"`
+	DeferredLines deferredFinancialEntryLines `json:"BankEntryLines"`
+}
+
+func (e BankEntry) BankEntryLines() []FinancialEntryLine {
+	return e.DeferredLines.Lines
+}
+
+func (e *BankEntry) SetBankEntryLines(lines []FinancialEntryLine) {
+	e.DeferredLines.Lines = lines
+}
+
+// CashEntry books money received or paid through the cash journal
+// JournalCode
+type CashEntry struct {
+	ID            string `json:"EntryID,omitempty"`
+	JournalCode   string
+	EntryNumber   int                         `json:",omitempty"`
+	DeferredLines deferredFinancialEntryLines `json:"CashEntryLines"`
+}
+
+func (e CashEntry) CashEntryLines() []FinancialEntryLine {
+	return e.DeferredLines.Lines
+}
+
+func (e *CashEntry) SetCashEntryLines(lines []FinancialEntryLine) {
+	e.DeferredLines.Lines = lines
+}
+
+var (
+	ErrFinancialEntryJournalRequired = errors.New("Field JournalCode is required on BankEntry and CashEntry")
+	ErrFinancialEntryLinesRequired   = errors.New("At least one line is required on BankEntry and CashEntry")
+)
+
+// saveFinancialEntry posts entry to uri and decodes the created entry into out
+func (c *Client) saveFinancialEntry(ctx context.Context, uri string, entry interface{}, out interface{}) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(uri, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := struct {
+		D json.RawMessage `json:"d"`
+	}{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.D, out)
+}
+
+func (e *BankEntry) Save(ctx context.Context, c *Client) error {
+	if e.JournalCode == "" {
+		return ErrFinancialEntryJournalRequired
+	}
+	if len(e.BankEntryLines()) == 0 {
+		return ErrFinancialEntryLinesRequired
+	}
+	return c.saveFinancialEntry(ctx, bankEntriesURI, e, e)
+}
+
+func (e *CashEntry) Save(ctx context.Context, c *Client) error {
+	if e.JournalCode == "" {
+		return ErrFinancialEntryJournalRequired
+	}
+	if len(e.CashEntryLines()) == 0 {
+		return ErrFinancialEntryLinesRequired
+	}
+	return c.saveFinancialEntry(ctx, cashEntriesURI, e, e)
+}
+
+func (c *Client) findFinancialEntryLines(ctx context.Context, uri string, f Filter) ([]FinancialEntryLine, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []FinancialEntryLine{}
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(uri, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindBankEntryLines returns the bank entry lines matching f
+func (c *Client) FindBankEntryLines(ctx context.Context, f Filter) ([]FinancialEntryLine, error) {
+	return c.findFinancialEntryLines(ctx, bankEntryLinesURI, f)
+}
+
+// FindCashEntryLines returns the cash entry lines matching f
+func (c *Client) FindCashEntryLines(ctx context.Context, f Filter) ([]FinancialEntryLine, error) {
+	return c.findFinancialEntryLines(ctx, cashEntryLinesURI, f)
+}
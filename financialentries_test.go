@@ -0,0 +1,77 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestBankEntrySave(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/financialtransaction/BankEntries" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		lines, ok := body["BankEntryLines"].([]interface{})
+		if !ok || len(lines) != 1 {
+			t.Errorf("Expected lines to be sent inline, got %#v", body["BankEntryLines"])
+		} else if line := lines[0].(map[string]interface{}); line["OurRef"] != float64(15000001) {
+			t.Errorf("Expected OurRef to be sent, got %#v", line)
+		}
+		w.WriteHeader(201)
+		fmt.Fprint(w, `{"d":{"EntryID":"entry-guid","JournalCode":"20","EntryNumber":20000001,"BankEntryLines":{"__deferred":{"uri":"lines"}}}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	e := BankEntry{}
+	if err := e.Save(context.Background(), cl); err != ErrFinancialEntryJournalRequired {
+		t.Errorf("Expected ErrFinancialEntryJournalRequired, got %#v", err)
+	}
+	e.JournalCode = "20"
+	if err := e.Save(context.Background(), cl); err != ErrFinancialEntryLinesRequired {
+		t.Errorf("Expected ErrFinancialEntryLinesRequired, got %#v", err)
+	}
+	e.SetBankEntryLines([]FinancialEntryLine{{Account: "account-guid", AmountFC: -10, OurRef: 15000001}})
+	if err := e.Save(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if e.ID != "entry-guid" || e.EntryNumber != 20000001 {
+		t.Errorf("Expected saved entry, got %#v", e)
+	}
+}
+
+func TestFindCashEntryLines(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/financialtransaction/CashEntryLines" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "Description eq 'Recras betaling 1'" {
+			t.Errorf("Unexpected filter %#v", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"line-guid","AmountFC":-5,"Date":"/Date(1405036800000)/","Description":"Recras betaling 1"}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	lines, err := cl.FindCashEntryLines(context.Background(), Eq("Description", "Recras betaling 1"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(lines) != 1 || lines[0].AmountFC != -5 {
+		t.Errorf("Unexpected lines %#v", lines)
+	}
+}
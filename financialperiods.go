@@ -0,0 +1,135 @@
+package exactonline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const (
+	financialPeriodURI = "/api/v1/%d/financial/FinancialPeriods"
+	journalStatusURI   = "/api/v1/%d/financial/JournalStatusByFinancialPeriod"
+)
+
+// FinancialPeriod is a period of a financial year. Entries can only be
+// booked on dates in a period that is not closed. Exact Online does not tell
+// whether a period is closed here, see JournalStatus and CloseJournal.
+type FinancialPeriod struct {
+	ID        string `json:",omitempty"`
+	FinYear   int
+	FinPeriod int
+	StartDate odata2json.Date
+	// EndDate is the last day of the period
+	EndDate odata2json.Date
+	// Closed is set by CloseJournal
+	Closed bool `json:"-"`
+}
+
+func (p FinancialPeriod) IsOpen() bool {
+	return !p.Closed
+}
+
+// Contains tells whether date falls in p
+func (p FinancialPeriod) Contains(date time.Time) bool {
+	return !date.Before(p.StartDate.Time) && !date.After(p.EndDate.Time)
+}
+
+// FinancialPeriodList is a list of periods ordered by StartDate
+type FinancialPeriodList []FinancialPeriod
+
+// GetFinancialPeriods returns the periods matching f ordered by StartDate, or
+// all of them when f is empty
+func (c *Client) GetFinancialPeriods(ctx context.Context, f Filter) (FinancialPeriodList, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := FinancialPeriodList{}
+	u := NewQuery().Filter(f).OrderBy("StartDate").URL(fmt.Sprintf(financialPeriodURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JournalStatus is the status of a journal in a financial period. Exact
+// Online refuses entries in a journal for a period it is closed for.
+type JournalStatus struct {
+	FinancialYear     int
+	FinancialPeriod   int
+	JournalCode       string
+	Status            int
+	StatusDescription string
+}
+
+const (
+	JournalStatusOpen   = 1
+	JournalStatusClosed = 2
+)
+
+func (s JournalStatus) IsClosed() bool {
+	return s.Status == JournalStatusClosed
+}
+
+// GetJournalStatuses returns the status of journal in every financial period
+func (c *Client) GetJournalStatuses(ctx context.Context, journal string) ([]JournalStatus, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []JournalStatus{}
+	u := NewQuery().Filter(Eq("JournalCode", journal)).URL(fmt.Sprintf(journalStatusURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CloseJournal returns a copy of l in which the periods closed for the
+// journal of statuses are Closed
+func (l FinancialPeriodList) CloseJournal(statuses []JournalStatus) FinancialPeriodList {
+	closed := map[[2]int]bool{}
+	for _, s := range statuses {
+		if s.IsClosed() {
+			closed[[2]int{s.FinancialYear, s.FinancialPeriod}] = true
+		}
+	}
+	out := make(FinancialPeriodList, len(l))
+	for i, p := range l {
+		p.Closed = closed[[2]int{p.FinYear, p.FinPeriod}]
+		out[i] = p
+	}
+	return out
+}
+
+var (
+	ErrFinancialPeriodNotFound = errors.New("No financial period for date")
+	ErrNoOpenFinancialPeriod   = errors.New("No open financial period after date")
+)
+
+// Find returns the period date falls in, or ErrFinancialPeriodNotFound when
+// its financial year does not exist
+func (l FinancialPeriodList) Find(date time.Time) (FinancialPeriod, error) {
+	for _, p := range l {
+		if p.Contains(date) {
+			return p, nil
+		}
+	}
+	return FinancialPeriod{}, ErrFinancialPeriodNotFound
+}
+
+// FirstOpenDate returns date when it falls in an open period, and else the
+// start of the first open period after it
+func (l FinancialPeriodList) FirstOpenDate(date time.Time) (time.Time, error) {
+	for _, p := range l {
+		if !p.IsOpen() || p.EndDate.Time.Before(date) {
+			continue
+		}
+		if p.Contains(date) {
+			return date, nil
+		}
+		return p.StartDate.Time, nil
+	}
+	return time.Time{}, ErrNoOpenFinancialPeriod
+}
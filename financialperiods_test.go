@@ -0,0 +1,95 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGetFinancialPeriods(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/financial/FinancialPeriods" {
+			t.Errorf("Expected path to be `/api/v1/123/financial/FinancialPeriods`, got `%s`", p)
+		}
+		if o := r.URL.Query().Get("$orderby"); o != "StartDate" {
+			t.Errorf("Expected periods to be ordered by StartDate, got %#v", o)
+		}
+		fmt.Fprint(w, `{"d":{"results":[
+			{"__metadata":{"uri":"https://start.exactonline.nl/api/v1/123/financial/FinancialPeriods(guid'p1')","type":"Exact.Web.Api.Models.Financial.FinancialPeriod"},"Created":"/Date(1419984000000)/","Creator":"creator-guid","CreatorFullName":"Recras","Division":123,"EndDate":"/Date(1422662400000)/","FinPeriod":1,"FinYear":2015,"ID":"p1","Modified":"/Date(1419984000000)/","Modifier":"creator-guid","ModifierFullName":"Recras","StartDate":"/Date(1420070400000)/"},
+			{"__metadata":{"uri":"https://start.exactonline.nl/api/v1/123/financial/FinancialPeriods(guid'p2')","type":"Exact.Web.Api.Models.Financial.FinancialPeriod"},"Created":"/Date(1419984000000)/","Creator":"creator-guid","CreatorFullName":"Recras","Division":123,"EndDate":"/Date(1425081600000)/","FinPeriod":2,"FinYear":2015,"ID":"p2","Modified":"/Date(1419984000000)/","Modifier":"creator-guid","ModifierFullName":"Recras","StartDate":"/Date(1422748800000)/"}
+		]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	periods, err := cl.GetFinancialPeriods(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("Expected 2 periods, got %#v", periods)
+	}
+	if !periods[0].IsOpen() {
+		t.Errorf("Expected periods to be open until a journal is closed, got %#v", periods[0])
+	}
+	// January closed, February open, no periods from March on
+	periods = periods.CloseJournal([]JournalStatus{
+		{FinancialYear: 2015, FinancialPeriod: 1, JournalCode: "recras", Status: JournalStatusClosed},
+		{FinancialYear: 2015, FinancialPeriod: 2, JournalCode: "recras", Status: JournalStatusOpen},
+	})
+
+	jan15 := time.Date(2015, 1, 15, 0, 0, 0, 0, time.UTC)
+	feb28 := time.Date(2015, 2, 28, 0, 0, 0, 0, time.UTC)
+	if p, err := periods.Find(jan15); err != nil || p.ID != "p1" || p.IsOpen() {
+		t.Errorf("Expected closed period p1, got %#v, %#v", p, err)
+	}
+	if _, err := periods.Find(time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)); err != ErrFinancialPeriodNotFound {
+		t.Errorf("Expected ErrFinancialPeriodNotFound, got %#v", err)
+	}
+
+	if d, err := periods.FirstOpenDate(jan15); err != nil || !d.Equal(time.Date(2015, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the start of February, got %s, %#v", d, err)
+	}
+	if d, err := periods.FirstOpenDate(feb28); err != nil || !d.Equal(feb28) {
+		t.Errorf("Expected the last day of an open period to stay, got %s, %#v", d, err)
+	}
+	if _, err := periods.FirstOpenDate(time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)); err != ErrNoOpenFinancialPeriod {
+		t.Errorf("Expected ErrNoOpenFinancialPeriod, got %#v", err)
+	}
+}
+
+func TestGetJournalStatuses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/financial/JournalStatusByFinancialPeriod" {
+			t.Errorf("Expected path to be `/api/v1/123/financial/JournalStatusByFinancialPeriod`, got `%s`", p)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "JournalCode eq 'recras'" {
+			t.Errorf("Expected statuses of the recras journal, got %#v", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[
+			{"__metadata":{"uri":"https://start.exactonline.nl/api/v1/123/financial/JournalStatusByFinancialPeriod(1)","type":"Exact.Web.Api.Models.Financial.JournalStatusByFinancialPeriod"},"Division":123,"FinancialPeriod":1,"FinancialYear":2015,"JournalCode":"recras","JournalCodeDescription":"Recras","JournalType":20,"JournalTypeDescription":"Sales","Status":2,"StatusDescription":"Closed"},
+			{"__metadata":{"uri":"https://start.exactonline.nl/api/v1/123/financial/JournalStatusByFinancialPeriod(2)","type":"Exact.Web.Api.Models.Financial.JournalStatusByFinancialPeriod"},"Division":123,"FinancialPeriod":2,"FinancialYear":2015,"JournalCode":"recras","JournalCodeDescription":"Recras","JournalType":20,"JournalTypeDescription":"Sales","Status":1,"StatusDescription":"Open"}
+		]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	statuses, err := cl.GetJournalStatuses(context.Background(), "recras")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(statuses) != 2 || !statuses[0].IsClosed() || statuses[1].IsClosed() || statuses[0].FinancialYear != 2015 {
+		t.Errorf("Expected period 1 closed and period 2 open, got %#v", statuses)
+	}
+}
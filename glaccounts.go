@@ -0,0 +1,128 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+const glAccountURI = "/api/v1/%d/financial/GLAccounts"
+
+// GLAccount is a general ledger account. Revenue accounts, the ones an
+// Item can book its GLRevenue on, have Type GLAccountTypeRevenue.
+type GLAccount struct {
+	ID          string `json:",omitempty"`
+	Code        string
+	Description string
+	Type        int    `json:",omitempty"`
+	BalanceSide string `json:",omitempty"`
+	BalanceType string `json:",omitempty"`
+	VATCode     string `json:",omitempty"`
+}
+
+const (
+	GLAccountTypeRevenue = 110
+
+	GLAccountBalanceSideCredit = "C"
+	GLAccountBalanceSideDebit  = "D"
+
+	GLAccountBalanceTypeBalanceSheet = "B"
+	GLAccountBalanceTypeProfitLoss   = "W"
+)
+
+type ErrGLAccountNotFound struct {
+	Division int
+	Code     string
+}
+
+func (e ErrGLAccountNotFound) Error() string {
+	return fmt.Sprintf("GLAccount not found for Code `%s` in Division %d", e.Code, e.Division)
+}
+
+// GetGLAccounts returns the GL accounts matching f ordered by Code, or all of
+// them when f is empty
+func (c *Client) GetGLAccounts(ctx context.Context, f Filter) ([]GLAccount, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []GLAccount{}
+	u := NewQuery().Filter(f).OrderBy("Code").URL(fmt.Sprintf(glAccountURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRevenueGLAccounts returns the GL accounts an Item can book revenue on
+func (c *Client) GetRevenueGLAccounts(ctx context.Context) ([]GLAccount, error) {
+	return c.GetGLAccounts(ctx, Eq("Type", GLAccountTypeRevenue))
+}
+
+// SearchGLAccounts returns the GL accounts with a Code starting with q or a
+// Description containing q
+func (c *Client) SearchGLAccounts(ctx context.Context, q string) ([]GLAccount, error) {
+	return c.GetGLAccounts(ctx, Or(StartsWith("Code", q), SubstringOf(q, "Description")))
+}
+
+func (c *Client) FindGLAccountByCode(ctx context.Context, code string) (GLAccount, error) {
+	code = strings.TrimSpace(code)
+	accounts, err := c.GetGLAccounts(ctx, Eq("Code", code))
+	if err != nil {
+		return GLAccount{}, err
+	}
+	if len(accounts) == 0 {
+		return GLAccount{}, ErrGLAccountNotFound{c.division, code}
+	}
+	return accounts[0], nil
+}
+
+var (
+	ErrGLAccountCodeRequired        = errors.New("Field `Code` on type `GLAccount` is mandatory")
+	ErrGLAccountDescriptionRequired = errors.New("Field `Description` on type `GLAccount` is mandatory")
+)
+
+func (g *GLAccount) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if g.Code == "" {
+		return ErrGLAccountCodeRequired
+	}
+	if g.Description == "" {
+		return ErrGLAccountDescriptionRequired
+	}
+
+	bs, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(glAccountURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]GLAccount{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*g = envelope["d"]
+	return nil
+}
+
+func (g *GLAccount) Update(ctx context.Context, c *Client, orig GLAccount) error {
+	return c.update(ctx, glAccountURI, g.ID, orig, *g, "ID")
+}
+
+func (g *GLAccount) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, glAccountURI, g.ID)
+}
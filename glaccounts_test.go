@@ -0,0 +1,89 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGetGLAccounts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/financial/GLAccounts" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		if o := r.URL.Query().Get("$orderby"); o != "Code" {
+			t.Errorf("Expected GLAccounts to be ordered by Code, got %#v", o)
+		}
+		switch r.URL.Query().Get("$filter") {
+		case "Type eq 110":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"gl-8000","Code":"8000","Description":"Omzet","Type":110},{"ID":"gl-8010","Code":"8010","Description":"Omzet arrangementen","Type":110}]}}`)
+		case "Code eq '8000'":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"gl-8000","Code":"8000","Description":"Omzet","Type":110}]}}`)
+		case "(startswith(Code, 'arr') eq true) or (substringof('arr', Description) eq true)":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"gl-8010","Code":"8010","Description":"Omzet arrangementen","Type":110}]}}`)
+		default:
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	gls, err := cl.GetRevenueGLAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(gls) != 2 {
+		t.Errorf("Expected 2 revenue accounts, got %#v", gls)
+	}
+
+	gl, err := cl.FindGLAccountByCode(context.Background(), " 8000")
+	if err != nil || gl.ID != "gl-8000" {
+		t.Errorf("Expected GLAccount 8000, got %#v, %#v", gl, err)
+	}
+	if _, err := cl.FindGLAccountByCode(context.Background(), "9999"); err != (ErrGLAccountNotFound{123, "9999"}) {
+		t.Errorf("Expected ErrGLAccountNotFound, got %#v", err)
+	}
+
+	gls, err = cl.SearchGLAccounts(context.Background(), "arr")
+	if err != nil || len(gls) != 1 || gls[0].Code != "8010" {
+		t.Errorf("Expected search to find 8010, got %#v, %#v", gls, err)
+	}
+}
+
+func TestGLAccountSave(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var gl GLAccount
+		json.NewDecoder(r.Body).Decode(&gl)
+		if gl.Code != "8020" || gl.Type != GLAccountTypeRevenue {
+			t.Errorf("Unexpected GLAccount %#v", gl)
+		}
+		w.WriteHeader(201)
+		fmt.Fprint(w, `{"d":{"ID":"gl-8020","Code":"8020","Description":"Omzet Recras","Type":110}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	gl := GLAccount{Code: "8020", Type: GLAccountTypeRevenue}
+	if err := gl.Save(context.Background(), cl); err != ErrGLAccountDescriptionRequired {
+		t.Errorf("Expected ErrGLAccountDescriptionRequired, got %#v", err)
+	}
+	gl.Description = "Omzet Recras"
+	if err := gl.Save(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if gl.ID != "gl-8020" {
+		t.Errorf("Expected ID to be set after save, got %#v", gl.ID)
+	}
+}
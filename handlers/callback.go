@@ -54,7 +54,7 @@ func GetCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	code := r.URL.Query().Get("code")
-	tok, err := exactonline.EnvConfig().Exchange(code)
+	tok, err := exactonline.EnvConfig().Exchange(r.Context(), code)
 	if err != nil {
 		callbackError(w, r, err)
 		return
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Recras/exactonline/dal"
+	"github.com/Recras/exactonline/libhttp"
+	"github.com/Recras/exactonline/synctool"
+	"github.com/Sirupsen/logrus"
+	"github.com/gorilla/context"
+	"github.com/jmoiron/sqlx"
+)
+
+// syncCostRules converts the stored cost rules of cred for synctool
+func syncCostRules(cred *dal.Credential, db *sqlx.DB) ([]synctool.CostRule, error) {
+	rules, err := cred.CostRules(db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]synctool.CostRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, synctool.CostRule{
+			ProductID:      r.ProductID,
+			ProductgroepID: r.ProductgroepID,
+			LocatieID:      r.LocatieID,
+			CostCenter:     r.CostCenter,
+			CostUnit:       r.CostUnit,
+		})
+	}
+	return out, nil
+}
+
+// formInt returns the integer form value name, or 0 when it is empty
+func formInt(r *http.Request, name string) (int, error) {
+	v := strings.TrimSpace(r.FormValue(name))
+	if v == "" {
+		return 0, nil
+	}
+	var i int
+	_, err := fmt.Sscan(v, &i)
+	return i, err
+}
+
+func PostCostRule(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	rule := dal.CostRule{
+		CostCenter: strings.TrimSpace(r.FormValue("CostCenter")),
+		CostUnit:   strings.TrimSpace(r.FormValue("CostUnit")),
+	}
+	var err1, err2, err3 error
+	rule.ProductID, err1 = formInt(r, "ProductID")
+	rule.ProductgroepID, err2 = formInt(r, "ProductgroepID")
+	rule.LocatieID, err3 = formInt(r, "LocatieID")
+	if err1 != nil || err2 != nil || err3 != nil || (rule.CostCenter == "" && rule.CostUnit == "") {
+		http.Error(w, "Ongeldige regel voor kostenplaats", http.StatusBadRequest)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostCostRule",
+		"recras_hostname": data.Hostname,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	if err := cred.AddCostRule(db, rule); err != nil {
+		logger.Errorf("error saving cost rule: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+func PostDeleteCostRule(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	var id int64
+	if _, err := fmt.Sscan(r.FormValue("ID"), &id); err != nil {
+		http.Error(w, "Ongeldige regel voor kostenplaats", http.StatusBadRequest)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostDeleteCostRule",
+		"recras_hostname": data.Hostname,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	if err := cred.DeleteCostRule(db, id); err != nil {
+		logger.Errorf("error deleting cost rule: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	gocontext "context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/Recras/exactonline"
 	"github.com/Recras/exactonline/dal"
+	"github.com/Recras/exactonline/httperror"
 	"github.com/Recras/exactonline/libhttp"
 	"github.com/Recras/exactonline/recras"
 	"github.com/Recras/exactonline/synctool"
@@ -21,6 +23,7 @@ import (
 )
 
 type administrationData struct {
+	RecrasBedrijfID   int
 	RecrasBedrijfNaam string
 	Error             string
 	EverythingOK      bool
@@ -35,6 +38,16 @@ type administrationData struct {
 	VATCodes   map[string]bool
 
 	PaymentConditionOK bool
+
+	Division int
+	// DivisionMapped is set when Division was chosen by the user instead of
+	// found by VAT number
+	DivisionMapped bool
+	Divisions      []exactonline.Division
+
+	RevenueAccountsOK bool
+	ProductsWithoutGL []recras.Product
+	RevenueGLAccounts []exactonline.GLAccount
 }
 
 func GetStatus(w http.ResponseWriter, r *http.Request) {
@@ -42,6 +55,11 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 		dashboardData
 		Administrations []administrationData
 		GeneralError    string
+		BookingMode     synctool.BookingMode
+		PeriodPolicy    synctool.ClosedPeriodPolicy
+		CostRules       []dal.CostRule
+		BankJournal     string
+		CashJournal     string
 	}{}
 	data.Administrations = []administrationData{}
 
@@ -73,11 +91,14 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 
 	db := context.Get(r, "db").(*sqlx.DB)
 	cred, err := dal.FindCredentialByRecrasHostname(db, recras_hostname)
-	tok := oauth2.Token{
-		RefreshToken: *cred.ExactRefreshToken,
+	data.BookingMode = bookingMode(cred)
+	data.PeriodPolicy = closedPeriodPolicy(cred)
+	if data.CostRules, err = cred.CostRules(db); err != nil {
+		logger.Errorf("error retrieving cost rules: %s", err)
 	}
-	cl := exactonline.EnvConfig().NewClient(tok)
-	err = cl.GetDefaultDivision()
+	data.BankJournal = cred.BankJournal
+	data.CashJournal = cred.CashJournal
+	cl, err := newExactClient(cred, db).DefaultDivision(r.Context())
 	if err != nil {
 		logger.Errorf("error retrieving currentdivision: %s", err)
 		w.WriteHeader(500)
@@ -87,7 +108,7 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rcl := recras.NewClient(cred.RecrasHostname, cred.RecrasUsername, cred.RecrasPassword)
-	bedrijven, err := rcl.GetBedrijven(nil)
+	bedrijven, err := rcl.GetBedrijven(r.Context(), nil)
 	if err != nil {
 		logger.Errorf("error retrieving bedrijven: %s", err)
 		w.WriteHeader(500)
@@ -96,45 +117,360 @@ func GetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var btw_percentages struct {
-		Waarde string `json:"waarde"`
+	percentages := recrasVATPercentages(r.Context(), &rcl)
+
+	producten, err := rcl.GetAllProducten(r.Context())
+	if err != nil {
+		logger.Errorf("error retrieving producten: %s", err)
+	}
+
+	divisions, err := cl.ListDivisions(r.Context())
+	if err != nil {
+		logger.Errorf("error retrieving divisions: %s", err)
+		w.WriteHeader(500)
+		data.GeneralError = "Verbindingsfout met Exact Online"
+		tmpl.Execute(w, data)
+		return
+	}
+	mappings, err := cred.DivisionMappings(db)
+	if err != nil {
+		logger.Errorf("error retrieving division mappings: %s", err)
 	}
-	rcl.Get("/api2/instellingen/btw_percentages", &btw_percentages)
-	percentages := strings.Split(btw_percentages.Waarde, ",")
 
 	for _, b := range bedrijven {
 		data.Administrations = append(data.Administrations, administrationData{
+			RecrasBedrijfID:   b.ID,
 			RecrasBedrijfNaam: b.Bedrijfsnaam,
+			Divisions:         divisions,
 		})
 		adminStatus := &data.Administrations[len(data.Administrations)-1]
 
-		err = cl.SetDivisionByVATNumber(b.BTWNummer)
-		if err == exactonline.ErrDivisionNotFound {
-			adminStatus.Error = "Geen administratie in Exact Online met BTW-nummer " + b.BTWNummer
-			continue
+		division, mapped := mappings[b.ID]
+		if !mapped {
+			div, err := exactonline.MatchDivisionByVATNumber(divisions, b.BTWNummer)
+			if err == exactonline.ErrDivisionNotFound {
+				adminStatus.Error = "Geen administratie in Exact Online met BTW-nummer " + b.BTWNummer
+				continue
+			}
+			division = div.Code
 		}
-		bedrijflogger := logger.WithField("exact_administration_id", cl.Division)
+		bcl := cl.WithDivision(division)
+		adminStatus.Division = division
+		adminStatus.DivisionMapped = mapped
+		bedrijflogger := logger.WithField("exact_administration_id", bcl.Division())
 		bedrijflogger.Info("Bedrijf gevonden")
 
-		itemgroup, err := cl.FindDefaultItemGroup()
+		itemgroup, err := bcl.FindDefaultItemGroup(r.Context())
 		if err != nil {
 			bedrijflogger.Info(err.Error())
 		}
 		adminStatus.DefaultItemGroupOK = (err == nil)
 		adminStatus.DefaultItemGroupCode = itemgroup.Code
 
-		checkDefaultJournal(cl, adminStatus, bedrijflogger)
-		checkVATCodes(percentages, cl, adminStatus, bedrijflogger)
-		checkPaymentCondition(cl, adminStatus, bedrijflogger)
+		checkDefaultJournal(r.Context(), bcl, adminStatus, bedrijflogger)
+		checkVATCodes(r.Context(), percentages, bcl, adminStatus, bedrijflogger)
+		checkPaymentCondition(r.Context(), bcl, adminStatus, bedrijflogger)
+		checkRevenueAccounts(r.Context(), producten, bcl, adminStatus, bedrijflogger)
+
+		adminStatus.EverythingOK = adminStatus.DefaultItemGroupOK && adminStatus.DefaultJournalOK && adminStatus.VATCodesOK && adminStatus.PaymentConditionOK && adminStatus.RevenueAccountsOK
+	}
+
+	tmpl.Execute(w, data)
+}
+
+// bookingMode returns the BookingMode of cred, credentials without one book
+// sales entries as they always have
+func bookingMode(cred *dal.Credential) synctool.BookingMode {
+	if cred.BookingMode == "" {
+		return synctool.BookSalesEntries
+	}
+	return synctool.BookingMode(cred.BookingMode)
+}
+
+// closedPeriodPolicy returns the ClosedPeriodPolicy of cred, skipping
+// invoices unless the user chose otherwise
+func closedPeriodPolicy(cred *dal.Credential) synctool.ClosedPeriodPolicy {
+	if cred.ClosedPeriodPolicy == "" {
+		return synctool.SkipClosedPeriods
+	}
+	return synctool.ClosedPeriodPolicy(cred.ClosedPeriodPolicy)
+}
+
+func PostClosedPeriodPolicy(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	policy := synctool.ClosedPeriodPolicy(r.FormValue("ClosedPeriodPolicy"))
+	if policy != synctool.SkipClosedPeriods && policy != synctool.BookOnFirstOpenDate {
+		http.Error(w, "Onbekende keuze voor afgesloten perioden", http.StatusBadRequest)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostClosedPeriodPolicy",
+		"recras_hostname": data.Hostname,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	if err := cred.UpdateClosedPeriodPolicy(db, string(policy)); err != nil {
+		logger.Errorf("error saving closed period policy: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+func PostBookingMode(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	mode := synctool.BookingMode(r.FormValue("BookingMode"))
+	if mode != synctool.BookSalesEntries && mode != synctool.BookSalesInvoices {
+		http.Error(w, "Onbekende boekingswijze", http.StatusBadRequest)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostBookingMode",
+		"recras_hostname": data.Hostname,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	if err := cred.UpdateBookingMode(db, string(mode)); err != nil {
+		logger.Errorf("error saving booking mode: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+func PostPaymentJournals(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostPaymentJournals",
+		"recras_hostname": data.Hostname,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	bank := strings.TrimSpace(r.FormValue("BankJournal"))
+	cash := strings.TrimSpace(r.FormValue("CashJournal"))
+	if err := cred.UpdatePaymentJournals(db, bank, cash); err != nil {
+		logger.Errorf("error saving payment journals: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+// checkRevenueAccounts lists the producten whose Item has no GLRevenue
+// account, which keeps their invoices from being synced. Producten without an
+// Item yet are left out; they get one on the next sync.
+func checkRevenueAccounts(ctx gocontext.Context, producten []recras.Product, cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
+	items, err := cl.GetAllItems(ctx)
+	if err != nil {
+		logger.Info(err.Error())
+		return
+	}
+	byCode := make(map[string]exactonline.Item, len(items))
+	for _, i := range items {
+		byCode[i.Code] = i
+	}
+	for _, p := range producten {
+		i, ok := byCode[fmt.Sprintf("recras%d", p.ID)]
+		if ok && i.GLRevenue == "" {
+			ad.ProductsWithoutGL = append(ad.ProductsWithoutGL, p)
+		}
+	}
+	ad.RevenueAccountsOK = len(ad.ProductsWithoutGL) == 0
+	if ad.RevenueAccountsOK {
+		return
+	}
+	ad.RevenueGLAccounts, err = cl.GetRevenueGLAccounts(ctx)
+	if err != nil {
+		logger.Info(err.Error())
+	}
+}
+
+func PostRevenueAccount(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
 
-		adminStatus.EverythingOK = adminStatus.DefaultItemGroupOK && adminStatus.DefaultJournalOK && adminStatus.VATCodesOK && adminStatus.PaymentConditionOK
+	var division, productID int
+	_, err1 := fmt.Sscan(r.FormValue("Division"), &division)
+	_, err2 := fmt.Sscan(r.FormValue("ProductID"), &productID)
+	glAccount := r.FormValue("GLAccount")
+	if err1 != nil || err2 != nil || glAccount == "" {
+		http.Error(w, "Ongeldige grootboekrekening", http.StatusBadRequest)
+		return
 	}
 
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostRevenueAccount",
+		"recras_hostname": data.Hostname,
+		"product_id":      productID,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	cl := newExactClient(cred, db).WithDivision(division)
+
+	item, err := cl.FindItemByRecrasID(r.Context(), productID)
+	if err != nil {
+		logger.Errorf("error retrieving item: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	orig := item
+	item.GLRevenue = glAccount
+	if err := item.Update(r.Context(), cl, orig); err != nil {
+		logger.Errorf("error saving revenue account: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+// PostDivisionMapping maps a Recras bedrijf to a division, or reverts it to
+// the division with its VAT number when Division is empty
+func PostDivisionMapping(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	var bedrijfID, division int
+	_, err := fmt.Sscan(r.FormValue("BedrijfID"), &bedrijfID)
+	if err == nil && r.FormValue("Division") != "" {
+		_, err = fmt.Sscan(r.FormValue("Division"), &division)
+	}
+	if err != nil {
+		http.Error(w, "Ongeldige administratie", http.StatusBadRequest)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostDivisionMapping",
+		"recras_hostname": data.Hostname,
+		"bedrijf_id":      bedrijfID,
+	})
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	if err := cred.UpdateDivisionMapping(db, bedrijfID, division); err != nil {
+		logger.Errorf("error saving division mapping: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	http.Redirect(w, r, "/status", 302)
+}
+
+func recrasVATPercentages(ctx gocontext.Context, rcl *recras.Client) []string {
+	var btw_percentages struct {
+		Waarde string `json:"waarde"`
+	}
+	rcl.Get(ctx, "/api2/instellingen/btw_percentages", &btw_percentages)
+	return strings.Split(btw_percentages.Waarde, ",")
+}
+
+// PostSetup creates the missing Exact Online setup of a division and shows
+// what was created
+func PostSetup(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		dashboardData
+		Report exactonline.SetupReport
+		Error  string
+	}{}
+	if !data.setDashboardData(r) {
+		http.Redirect(w, r, "/logout", 302)
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"function":        "handlers.PostSetup",
+		"recras_hostname": data.Hostname,
+	})
+	tmpl, err := template.ParseFiles("templates/dashboard.html.tmpl", "templates/setup.html.tmpl")
+	if err != nil {
+		libhttp.HandleErrorJson(w, err)
+		logger.Errorf("template parsing error %s", err)
+		return
+	}
+
+	var division int
+	if _, err := fmt.Sscan(r.FormValue("Division"), &division); err != nil {
+		http.Error(w, "Ongeldige administratie", http.StatusBadRequest)
+		return
+	}
+
+	db := context.Get(r, "db").(*sqlx.DB)
+	cred, err := dal.FindCredentialByRecrasHostname(db, data.Hostname)
+	if err != nil {
+		logger.Errorf("error retrieving credentials: %s", err)
+		libhttp.HandleErrorJson(w, err)
+		return
+	}
+	cl := newExactClient(cred, db).WithDivision(division)
+	rcl := recras.NewClient(cred.RecrasHostname, cred.RecrasUsername, cred.RecrasPassword)
+
+	data.Report, err = cl.SetupAdministration(r.Context(), recrasVATPercentages(r.Context(), &rcl))
+	if err != nil {
+		logger.WithField("exact_administration_id", division).Errorf("error setting up administration: %s", err)
+		data.Error = httperror.Message(err)
+		w.WriteHeader(500)
+	}
 	tmpl.Execute(w, data)
 }
 
-func checkDefaultJournal(cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
-	j, err := cl.FindDefaultJournal()
+func checkDefaultJournal(ctx gocontext.Context, cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
+	j, err := cl.FindDefaultJournal(ctx)
 	if err != nil && err != exactonline.ErrJournalNotFound {
 		logrus.Errorf("%#v", err)
 	}
@@ -142,8 +478,8 @@ func checkDefaultJournal(cl *exactonline.Client, ad *administrationData, logger
 	ad.DefaultJournalDesc = j.Description
 }
 
-func checkVATCodes(percentages []string, cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
-	codes, err := cl.GetRecrasVATCodes()
+func checkVATCodes(ctx gocontext.Context, percentages []string, cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
+	codes, err := cl.GetRecrasVATCodes(ctx)
 	if err != nil {
 		logrus.Errorf("VATCodes: %#v", err)
 		return
@@ -164,42 +500,85 @@ func checkVATCodes(percentages []string, cl *exactonline.Client, ad *administrat
 	}
 }
 
-func checkPaymentCondition(cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
-	_, err := cl.FindPaymentConditionByDescription("recras")
+func checkPaymentCondition(ctx gocontext.Context, cl *exactonline.Client, ad *administrationData, logger *logrus.Entry) {
+	_, err := cl.FindPaymentConditionByDescription(ctx, "recras")
 	if err != nil {
 		logrus.Errorf("PaymentCondition: %#v", err)
 	}
 	ad.PaymentConditionOK = (err == nil)
 }
 
-// SyncRecras performs the synchronisation of a single Recras instance
-func SyncRecras(cred *dal.Credential, entry *logrus.Entry, db *sqlx.DB) {
+// newExactClient creates an Exact Online client for cred. Exact rotates the
+// refresh token on every refresh, so each new token is stored right away.
+func newExactClient(cred *dal.Credential, db *sqlx.DB) *exactonline.Client {
+	cl := exactonline.EnvConfig().NewClient(oauth2.Token{RefreshToken: *cred.ExactRefreshToken})
+	cl.TokenSource.OnRefresh = func(tok *oauth2.Token) error {
+		return cred.UpdateToken(db, tok.AccessToken, tok.RefreshToken)
+	}
+	return cl
+}
+
+// syncParallel is the number of Recras bedrijven synced at the same time
+const syncParallel = 3
+
+// SyncRecras performs the synchronisation of a single Recras instance. The
+// sync stops between invoices when ctx is cancelled.
+func SyncRecras(ctx gocontext.Context, cred *dal.Credential, entry *logrus.Entry, db *sqlx.DB) {
 	if cred.ExactRefreshToken == nil {
 		entry.Errorf("handlers.SyncRecras: no Exact Refresh Token, please run the activation again")
 		return
 	}
-	cl := exactonline.EnvConfig().NewClient(oauth2.Token{RefreshToken: *cred.ExactRefreshToken})
-	err := cl.GetDefaultDivision()
+	cl, err := newExactClient(cred, db).DefaultDivision(ctx)
 	if err != nil {
 		entry.Errorf("handlers.GetStatus: error retrieving currentdivision: %s", err)
+		cl = newExactClient(cred, db)
+	}
+
+	mappings, err := cred.DivisionMappings(db)
+	if err != nil {
+		// syncing mapped bedrijven by VAT number could book in the wrong division
+		entry.Errorf("handlers.SyncRecras: error retrieving division mappings: %s", err)
+		return
+	}
+	costRules, err := syncCostRules(cred, db)
+	if err != nil {
+		entry.Errorf("handlers.SyncRecras: error retrieving cost rules: %s", err)
+		return
 	}
 
 	rcl := recras.NewClient(cred.RecrasHostname, cred.RecrasUsername, cred.RecrasPassword)
 
 	errc := make(chan error)
 	go func() {
-		f := cred.StartSync.Format("2006-01-02")
-		synctool.Sync(entry, errc, &rcl, cl, f)
+		opts := synctool.Options{
+			StartDate:          cred.StartSync.Format("2006-01-02"),
+			BookingMode:        bookingMode(cred),
+			BankJournal:        cred.BankJournal,
+			CashJournal:        cred.CashJournal,
+			Parallel:           syncParallel,
+			Divisions:          mappings,
+			ClosedPeriodPolicy: closedPeriodPolicy(cred),
+			CostRules:          costRules,
+		}
+		synctool.Sync(ctx, entry, errc, &rcl, cl, opts)
 		close(errc)
 	}()
 	messagebuf := bytes.NewBuffer(nil)
 	for e := range errc {
-		logrus.Infof(e.Error())
-		fmt.Fprintf(messagebuf, "%s<br>\n", e.Error())
+		logrus.Info(e.Error())
+		fmt.Fprintf(messagebuf, "%s<br>\n", httperror.Message(e))
+	}
+	for division, rl := range cl.RateLimits() {
+		entry.WithFields(logrus.Fields{
+			"exact_administration_id": division,
+			"daily_remaining":         rl.DailyRemaining,
+			"daily_limit":             rl.DailyLimit,
+		}).Info("Exact Online rate limit")
+		fmt.Fprintf(messagebuf, "Exact Online API-limiet administratie %d: nog %d van %d verzoeken vandaag<br>\n", division, rl.DailyRemaining, rl.DailyLimit)
 	}
 
-	personeel, _ := rcl.GetCurrentPersoneel()
-	gebruiker, _ := rcl.GetGebruiker(personeel)
+	personeel, _ := rcl.GetCurrentPersoneel(ctx)
+	gebruiker, _ := rcl.GetGebruiker(ctx, personeel)
 	now := time.Now().Truncate(time.Hour)
 
 	contactmoment := recras.Contactmoment{
@@ -212,19 +591,10 @@ func SyncRecras(cred *dal.Credential, entry *logrus.Entry, db *sqlx.DB) {
 		ContactOpnemenGroup:     gebruiker.GetFirstRolId(),
 		ContactOpnemenOpmerking: "Synchronisatierapport Exact Online",
 	}
-	err = contactmoment.Save(&rcl)
+	err = contactmoment.Save(ctx, &rcl)
 	if err != nil {
 		entry.WithField("recras_personeel", personeel.Displaynaam).Error("error saving contactmoment: " + err.Error())
 	}
-
-	tok, err := cl.TokenSource.Token()
-	if err != nil {
-		entry.Errorf("SyncRecras: error getting token")
-	}
-	err = cred.UpdateToken(db, tok.AccessToken, tok.RefreshToken)
-	if err != nil {
-		entry.Errorf("SyncRecras: error updating token")
-	}
 	entry.Info("Synchronization done")
 }
 
@@ -251,5 +621,5 @@ func GetSync(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Inloggegevens Exact Online konden niet gevonden worden voor %s", recras_hostname)
 	}
 
-	SyncRecras(cred, entry, db)
+	SyncRecras(r.Context(), cred, entry, db)
 }
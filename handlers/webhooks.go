@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/Recras/exactonline"
+	"github.com/Sirupsen/logrus"
+)
+
+// ExactWebhooks creates the receiver for Exact Online webhook calls, signed
+// with the client secret of the app
+func ExactWebhooks() *exactonline.WebhookHandler {
+	h := exactonline.NewWebhookHandler(exactonline.EnvConfig().Oauth.ClientSecret)
+	h.Handle("SalesEntries", logWebhookEvent)
+	h.Handle("Accounts", logWebhookEvent)
+	return h
+}
+
+func logWebhookEvent(ctx context.Context, ev exactonline.WebhookEvent) error {
+	logrus.WithFields(logrus.Fields{
+		"exact_administration_id": ev.Division,
+		"topic":                   ev.Topic,
+		"action":                  ev.Action,
+		"key":                     ev.Key,
+	}).Info("Exact Online webhook")
+	return nil
+}
@@ -1,30 +1,129 @@
 package httperror
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"net/http"
 	"io/ioutil"
+	"net/http"
+)
+
+// Kind classifies an HTTPError by its status code
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindValidation
+	KindAuth
+	KindNotFound
+	KindRateLimit
+	KindServer
 )
 
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindAuth:
+		return "auth"
+	case KindNotFound:
+		return "not found"
+	case KindRateLimit:
+		return "rate limit"
+	case KindServer:
+		return "server"
+	}
+	return "unknown"
+}
+
+// HTTPError is returned for a response with an unexpected status code. The
+// bodies of the request and response are buffered, so they can be read as
+// often as needed. Code, Message and Lang are filled from an OData error
+// payload when the response has one.
 type HTTPError struct {
-	StatusCode int
-	Request    *http.Request
-	Response   *http.Response
+	StatusCode  int
+	Request     *http.Request
+	Response    *http.Response
+	Body        []byte
+	RequestBody []byte
+
+	Code    string
+	Message string
+	Lang    string
+}
+
+type odataError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message struct {
+			Lang  string `json:"lang"`
+			Value string `json:"value"`
+		} `json:"message"`
+	} `json:"error"`
+}
+
+// Kind returns the class of the error
+func (e HTTPError) Kind() Kind {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return KindAuth
+	case e.StatusCode == http.StatusNotFound:
+		return KindNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return KindRateLimit
+	case e.StatusCode >= 500:
+		return KindServer
+	case e.StatusCode >= 400:
+		return KindValidation
+	}
+	return KindUnknown
 }
 
 func (e HTTPError) Error() string {
-	defer e.Response.Body.Close()
-	body, err := ioutil.ReadAll(e.Response.Body)
-	if err != nil {
-		return fmt.Sprintf("ioutil.ReadAll failed, no response body, HTTP error %d for %s %s", e.StatusCode, e.Request.Method, e.Request.URL)
+	msg := e.Message
+	if msg == "" {
+		msg = string(e.Body)
 	}
-	return fmt.Sprintf("HTTP error %d for %s %s: %s", e.StatusCode, e.Request.Method, e.Request.URL, body)
+	if e.Request == nil {
+		return fmt.Sprintf("HTTP error %d: %s", e.StatusCode, msg)
+	}
+	return fmt.Sprintf("HTTP error %d for %s %s: %s", e.StatusCode, e.Request.Method, e.Request.URL, msg)
 }
 
+// New buffers the body of resp and parses it into an HTTPError. The body of
+// resp is replaced by the buffered copy.
 func New(resp *http.Response) HTTPError {
-	return HTTPError{
+	e := HTTPError{
 		StatusCode: resp.StatusCode,
 		Request:    resp.Request,
 		Response:   resp,
 	}
+	if resp.Body != nil {
+		e.Body, _ = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(e.Body))
+	}
+	if req := resp.Request; req != nil && req.GetBody != nil {
+		if rb, err := req.GetBody(); err == nil {
+			e.RequestBody, _ = ioutil.ReadAll(rb)
+			rb.Close()
+		}
+	}
+
+	var oe odataError
+	if json.Unmarshal(e.Body, &oe) == nil {
+		e.Code = oe.Error.Code
+		e.Message = oe.Error.Message.Value
+		e.Lang = oe.Error.Message.Lang
+	}
+	return e
+}
+
+// Message returns the message of err when it is an HTTPError with a parsed
+// error payload, and err.Error() otherwise
+func Message(err error) string {
+	if e, ok := err.(HTTPError); ok && e.Message != "" {
+		return e.Message
+	}
+	return err.Error()
 }
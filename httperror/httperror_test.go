@@ -0,0 +1,79 @@
+package httperror
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const exactError = `{"error":{"code":"","message":{"lang":"en-US","value":"Customer is blocked"}}}`
+
+func TestNew_ODataError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		fmt.Fprint(w, exactError)
+	}))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", strings.NewReader(`{"Customer":"guid"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(resp)
+	if e.Message != "Customer is blocked" || e.Lang != "en-US" {
+		t.Errorf("Expected error message to be parsed, got %#v", e)
+	}
+	if e.Kind() != KindValidation {
+		t.Errorf("Expected validation error, got %s", e.Kind())
+	}
+	if string(e.RequestBody) != `{"Customer":"guid"}` {
+		t.Errorf("Expected request body to be kept, got %#v", string(e.RequestBody))
+	}
+	if !strings.HasSuffix(e.Error(), ": Customer is blocked") {
+		t.Errorf("Expected Error to show the message, got %#v", e.Error())
+	}
+	body, _ := ioutil.ReadAll(e.Response.Body)
+	if string(body) != exactError {
+		t.Errorf("Expected response body to be readable after Error, got %#v", string(body))
+	}
+	if Message(e) != "Customer is blocked" {
+		t.Errorf("Expected Message to return the parsed message, got %#v", Message(e))
+	}
+}
+
+func TestNew_PlainBody(t *testing.T) {
+	e := New(&http.Response{
+		StatusCode: 500,
+		Body:       ioutil.NopCloser(strings.NewReader("Internal Server Error")),
+	})
+	if e.Message != "" {
+		t.Errorf("Expected no message, got %#v", e.Message)
+	}
+	if e.Error() != "HTTP error 500: Internal Server Error" {
+		t.Errorf("Expected Error to show the body, got %#v", e.Error())
+	}
+	if Message(e) != e.Error() {
+		t.Errorf("Expected Message to fall back to Error, got %#v", Message(e))
+	}
+}
+
+func TestKind(t *testing.T) {
+	cases := map[int]Kind{
+		400: KindValidation,
+		401: KindAuth,
+		403: KindAuth,
+		404: KindNotFound,
+		429: KindRateLimit,
+		500: KindServer,
+		503: KindServer,
+		302: KindUnknown,
+	}
+	for status, k := range cases {
+		if g := (HTTPError{StatusCode: status}).Kind(); g != k {
+			t.Errorf("Expected status %d to be %s, got %s", status, k, g)
+		}
+	}
+}
@@ -2,10 +2,10 @@ package exactonline
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 )
 import (
 	"github.com/Recras/exactonline/httperror"
@@ -19,9 +19,13 @@ type Item struct {
 	Code        string
 	Description string
 	StartDate   odata2json.Date
+	EndDate     odata2json.NullDate
 	IsSalesItem bool
 	Unit        string `json:",omitempty"`
 	GLRevenue   string `json:",omitempty"`
+	// ItemGroup is the ID of the ItemGroup of the item
+	ItemGroup    string `json:",omitempty"`
+	SalesVatCode string `json:",omitempty"`
 }
 
 type items struct {
@@ -30,28 +34,31 @@ type items struct {
 	} `json:"d"`
 }
 
-func (c *Client) GetAllItems() ([]Item, error) {
-	if c.Division == 0 {
+func (c *Client) GetAllItems(ctx context.Context) ([]Item, error) {
+	if c.division == 0 {
 		return nil, ErrNoDivision
 	}
-	u := fmt.Sprintf(itemURI, c.Division)
-	resp, err := c.Client.Get(u)
+	out := []Item{}
+	err := c.Iterate(ctx, fmt.Sprintf(itemURI, c.division)).All(&out)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return nil, httperror.New(resp)
-	}
-
-	out := &items{}
+	return out, nil
+}
 
-	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(out)
-	if err != nil {
-		return nil, err
+// EachItem calls fn for every Item in the division, one page at a time.
+// Returning ErrStopIteration from fn stops early.
+func (c *Client) EachItem(ctx context.Context, fn func(Item) error) error {
+	if c.division == 0 {
+		return ErrNoDivision
 	}
-
-	return out.D.Results, nil
+	return c.Iterate(ctx, fmt.Sprintf(itemURI, c.division)).Each(func(raw json.RawMessage) error {
+		var i Item
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return err
+		}
+		return fn(i)
+	})
 }
 
 type ErrItemNotFound struct {
@@ -64,17 +71,15 @@ func (e ErrItemNotFound) Error() string {
 }
 
 type ItemFinder interface {
-	FindItemByRecrasID(int) (Item, error)
+	FindItemByRecrasID(context.Context, int) (Item, error)
 }
 
-func (c *Client) FindItemByRecrasID(recrasID int) (Item, error) {
-	if c.Division == 0 {
+func (c *Client) FindItemByRecrasID(ctx context.Context, recrasID int) (Item, error) {
+	if c.division == 0 {
 		return Item{}, ErrNoDivision
 	}
-	u := fmt.Sprintf(itemURI, c.Division)
-	filt := url.Values{}
-	filt.Set("$filter", fmt.Sprintf("Code eq 'recras%d'", recrasID))
-	resp, err := c.Client.Get(fmt.Sprintf("%s?%s", u, filt.Encode()))
+	u := fmt.Sprintf(itemURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(Eq("Code", fmt.Sprintf("recras%d", recrasID))).URL(u))
 	if err != nil {
 		return Item{}, err
 	}
@@ -90,7 +95,7 @@ func (c *Client) FindItemByRecrasID(recrasID int) (Item, error) {
 		return Item{}, err
 	}
 	if len(out.D.Results) == 0 {
-		return Item{}, ErrItemNotFound{c.Division, recrasID}
+		return Item{}, ErrItemNotFound{c.division, recrasID}
 	}
 
 	return out.D.Results[0], nil
@@ -102,8 +107,8 @@ var (
 	ErrItemUnitRequired        = errors.New("Field `Unit` on type `Item` is mandatory")
 )
 
-func (i *Item) Save(c *Client) error {
-	if c.Division == 0 {
+func (i *Item) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
 		return ErrNoDivision
 	}
 	if i.Code == "" {
@@ -122,7 +127,7 @@ func (i *Item) Save(c *Client) error {
 	}
 
 	bb := bytes.NewBuffer(bs)
-	resp, err := c.Client.Post(fmt.Sprintf(itemURI, c.Division), "application/json", bb)
+	resp, err := c.post(ctx, fmt.Sprintf(itemURI, c.division), bb)
 	if err != nil {
 		return err
 	}
@@ -146,8 +151,9 @@ func (i *Item) Save(c *Client) error {
 const itemGroupURI = "/api/v1/%d/logistics/ItemGroups"
 
 type ItemGroup struct {
-	ID   string
-	Code string
+	ID          string `json:",omitempty"`
+	Code        string
+	Description string `json:",omitempty"`
 }
 type itemgroups struct {
 	D struct {
@@ -157,11 +163,9 @@ type itemgroups struct {
 
 var ErrNoDefaultItemGroup = errors.New("No default ItemGroup")
 
-func (c *Client) FindDefaultItemGroup() (ItemGroup, error) {
-	u := fmt.Sprintf(itemGroupURI, c.Division)
-	filt := url.Values{}
-	filt.Set("$filter", "IsDefault eq 1")
-	resp, err := c.Client.Get(fmt.Sprintf("%s?%s", u, filt.Encode()))
+func (c *Client) FindDefaultItemGroup(ctx context.Context) (ItemGroup, error) {
+	u := fmt.Sprintf(itemGroupURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(Eq("IsDefault", 1)).URL(u))
 	if err != nil {
 		return ItemGroup{}, err
 	}
@@ -181,3 +185,92 @@ func (c *Client) FindDefaultItemGroup() (ItemGroup, error) {
 	}
 	return out.D.Results[0], nil
 }
+
+func (c *Client) GetItemGroups(ctx context.Context) ([]ItemGroup, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []ItemGroup{}
+	if err := c.Iterate(ctx, fmt.Sprintf(itemGroupURI, c.division)).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type ErrItemGroupNotFound struct {
+	Division int
+	Code     string
+}
+
+func (e ErrItemGroupNotFound) Error() string {
+	return fmt.Sprintf("ItemGroup not found for Code `%s` in Division %d", e.Code, e.Division)
+}
+
+func (c *Client) FindItemGroupByCode(ctx context.Context, code string) (ItemGroup, error) {
+	if c.division == 0 {
+		return ItemGroup{}, ErrNoDivision
+	}
+	out := []ItemGroup{}
+	u := NewQuery().Filter(Eq("Code", code)).URL(fmt.Sprintf(itemGroupURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return ItemGroup{}, err
+	}
+	if len(out) == 0 {
+		return ItemGroup{}, ErrItemGroupNotFound{c.division, code}
+	}
+	return out[0], nil
+}
+
+var (
+	ErrItemGroupCodeRequired        = errors.New("Field `Code` on type `ItemGroup` is mandatory")
+	ErrItemGroupDescriptionRequired = errors.New("Field `Description` on type `ItemGroup` is mandatory")
+)
+
+func (g *ItemGroup) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if g.Code == "" {
+		return ErrItemGroupCodeRequired
+	}
+	if g.Description == "" {
+		return ErrItemGroupDescriptionRequired
+	}
+
+	bs, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(itemGroupURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]ItemGroup{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*g = envelope["d"]
+	return nil
+}
+
+func (i *Item) Update(ctx context.Context, c *Client, orig Item) error {
+	return c.update(ctx, itemURI, i.ID, orig, *i, "ID")
+}
+
+func (i *Item) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, itemURI, i.ID)
+}
+
+func (g *ItemGroup) Update(ctx context.Context, c *Client, orig ItemGroup) error {
+	return c.update(ctx, itemGroupURI, g.ID, orig, *g, "ID")
+}
+
+func (g *ItemGroup) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, itemGroupURI, g.ID)
+}
@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -22,10 +23,10 @@ func TestGetAllItems(t *testing.T) {
 	defer ts.Close()
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	items, err := cl.GetAllItems()
+	cl.division = 123
+	items, err := cl.GetAllItems(context.Background())
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -38,7 +39,7 @@ func TestGetAllItems(t *testing.T) {
 
 func TestGetAllItems_NoDivision(t *testing.T) {
 	cl := Client{}
-	_, err := cl.GetAllItems()
+	_, err := cl.GetAllItems(context.Background())
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -58,10 +59,10 @@ func TestFindItemByRecrasID(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	item, err := cl.FindItemByRecrasID(12)
+	cl.division = 123
+	item, err := cl.FindItemByRecrasID(context.Background(), 12)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -83,10 +84,10 @@ func TestFindItemByRecrasID_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	_, err := cl.FindItemByRecrasID(12)
+	cl.division = 123
+	_, err := cl.FindItemByRecrasID(context.Background(), 12)
 	if err == nil {
 		t.Errorf("Expected error")
 	} else if _, ok := err.(ErrItemNotFound); !ok {
@@ -96,7 +97,7 @@ func TestFindItemByRecrasID_NotFound(t *testing.T) {
 
 func TestFindItemByRecrasID_NoDivision(t *testing.T) {
 	cl := Client{}
-	_, err := cl.FindItemByRecrasID(12)
+	_, err := cl.FindItemByRecrasID(context.Background(), 12)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -105,7 +106,7 @@ func TestFindItemByRecrasID_NoDivision(t *testing.T) {
 func TestSaveItem_NoDivision(t *testing.T) {
 	cl := &Client{}
 	i := Item{}
-	err := i.Save(cl)
+	err := i.Save(context.Background(), cl)
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
@@ -154,33 +155,33 @@ func TestSaveItem(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
+	cl.division = 123
 
 	a := Item{}
 
-	if err := a.Save(cl); err != ErrItemCodeRequired {
+	if err := a.Save(context.Background(), cl); err != ErrItemCodeRequired {
 		t.Errorf("Expected ErrItemCodeRequired, got %#v", err)
 	}
 
 	a.Code = "code"
-	if err := a.Save(cl); err != ErrItemDescriptionRequired {
+	if err := a.Save(context.Background(), cl); err != ErrItemDescriptionRequired {
 		t.Errorf("Expected ErrItemDescriptionRequired, %#v", err)
 	}
 
 	a.Description = "desc"
-	if err := a.Save(cl); err != ErrItemUnitRequired {
+	if err := a.Save(context.Background(), cl); err != ErrItemUnitRequired {
 		t.Errorf("Expected ErrItemUnitRequired, got %#v", err)
 	}
 
 	a.Unit = "recras"
 
-	a.Save(cl)
+	a.Save(context.Background(), cl)
 	if !apicalled {
 		t.Errorf("Expected API to be called")
 	}
-	if err := a.Save(cl); err != nil {
+	if err := a.Save(context.Background(), cl); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
 
@@ -203,11 +204,11 @@ func TestFindDefaultItemGroup(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
+	cl.division = 123
 
-	itemgroup, err := cl.FindDefaultItemGroup()
+	itemgroup, err := cl.FindDefaultItemGroup(context.Background())
 	if !apicalled {
 		t.Errorf("Expected API to be called")
 	}
@@ -227,11 +228,89 @@ func TestFindDefaultItemGroup_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	_, err := cl.FindDefaultItemGroup()
+	cl.division = 123
+	_, err := cl.FindDefaultItemGroup(context.Background())
 	if err != ErrNoDefaultItemGroup {
 		t.Errorf("Expected ErrNoDefaultItemGroup, got %#v", err)
 	}
 }
+
+func TestGetAllItems_multiplePages(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skiptoken") == "" {
+			fmt.Fprintf(w, `{"d":{"results":[{"ID":"guid1"}],"__next":"%s/api/v1/123/logistics/Items?$skiptoken=guid'guid1'"}}`, ts.URL)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"guid2"}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{
+		Expiry: time.Now().Add(time.Minute),
+	})
+	cl.division = 123
+	items, err := cl.GetAllItems(context.Background())
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("Expected items of both pages, got %#v", items)
+	}
+
+	count := 0
+	err = cl.EachItem(context.Background(), func(i Item) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected EachItem to be called for 2 items, got %d", count)
+	}
+}
+
+func TestFindItemGroupByCode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			payload := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&payload)
+			if payload["Code"] != "recras3" || payload["Description"] != "Activiteiten" {
+				t.Errorf("Unexpected payload %#v", payload)
+			}
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"ID":"guid","Code":"recras3","Description":"Activiteiten"}}`)
+			return
+		}
+		if f := r.URL.Query().Get("$filter"); f != "Code eq 'recras3'" {
+			t.Errorf("Expected $filter to be `Code eq 'recras3'`, got `%s`", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	_, err := cl.FindItemGroupByCode(context.Background(), "recras3")
+	if _, ok := err.(ErrItemGroupNotFound); !ok {
+		t.Errorf("Expected ErrItemGroupNotFound, got %#v", err)
+	}
+
+	g := ItemGroup{Code: "recras3"}
+	if err := g.Save(context.Background(), cl); err != ErrItemGroupDescriptionRequired {
+		t.Errorf("Expected ErrItemGroupDescriptionRequired, got %#v", err)
+	}
+	g.Description = "Activiteiten"
+	if err := g.Save(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if g.ID != "guid" {
+		t.Errorf("Expected ID to be set, got %#v", g.ID)
+	}
+}
@@ -0,0 +1,127 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+// ErrStopIteration can be returned from an Each callback to stop iterating
+// without returning an error
+var ErrStopIteration = errors.New("exactonline: stop iteration")
+
+type page struct {
+	D struct {
+		Results []json.RawMessage `json:"results"`
+		Next    string            `json:"__next"`
+	} `json:"d"`
+}
+
+// Iterator walks over the results of an OData collection, following the
+// d.__next links Exact Online returns when a result spans multiple pages.
+// Only a single page is held in memory at a time.
+//
+//	it := cl.Iterate(ctx, u)
+//	for it.Next() {
+//		var i Item
+//		if err := it.Decode(&i); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator struct {
+	c       *Client
+	ctx     context.Context
+	next    string
+	results []json.RawMessage
+	current json.RawMessage
+	err     error
+}
+
+// Iterate returns an Iterator over the collection at URL u
+func (c *Client) Iterate(ctx context.Context, u string) *Iterator {
+	return &Iterator{c: c, ctx: ctx, next: u}
+}
+
+func (it *Iterator) fetch() error {
+	resp, err := it.c.get(it.ctx, it.next)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return httperror.New(resp)
+	}
+
+	p := &page{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(p); err != nil {
+		return err
+	}
+	it.results = p.D.Results
+	it.next = p.D.Next
+	return nil
+}
+
+// Next advances to the next result, fetching the next page if needed. It
+// returns false when the results are exhausted or an error occurred.
+func (it *Iterator) Next() bool {
+	for len(it.results) == 0 {
+		if it.err != nil || it.next == "" {
+			it.current = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+	}
+	it.current = it.results[0]
+	it.results = it.results[1:]
+	return true
+}
+
+// Decode unmarshals the current result into v
+func (it *Iterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errors.New("exactonline: Decode called without a current result")
+	}
+	return json.Unmarshal(it.current, v)
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Each calls fn with every remaining raw result. Returning ErrStopIteration
+// from fn stops early.
+func (it *Iterator) Each(fn func(json.RawMessage) error) error {
+	for it.Next() {
+		if err := fn(it.current); err == ErrStopIteration {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// All decodes all remaining results into out, which must be a pointer to a slice
+func (it *Iterator) All(out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.New("exactonline: Iterator.All needs a pointer to a slice")
+	}
+	slice := v.Elem()
+	return it.Each(func(raw json.RawMessage) error {
+		e := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal(raw, e.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, e.Elem()))
+		return nil
+	})
+}
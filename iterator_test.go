@@ -0,0 +1,114 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline/httperror"
+	"golang.org/x/oauth2"
+)
+
+func pagedServer(pages []string) (*httptest.Server, *int) {
+	fetched := 0
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		p := 0
+		fmt.Sscanf(r.URL.Query().Get("$skiptoken"), "%d", &p)
+		next := ""
+		if p+1 < len(pages) {
+			next = fmt.Sprintf(`, "__next": "%s/api/v1/1/logistics/Items?$skiptoken=%d"`, ts.URL, p+1)
+		}
+		fmt.Fprintf(w, `{"d":{"results":[%s]%s}}`, pages[p], next)
+	}))
+	return ts, &fetched
+}
+
+func TestIterator_multiplePages(t *testing.T) {
+	ts, fetched := pagedServer([]string{
+		`{"ID":"guid1"},{"ID":"guid2"}`,
+		``,
+		`{"ID":"guid3"}`,
+	})
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	it := cl.Iterate(context.Background(), "/api/v1/1/logistics/Items")
+	ids := []string{}
+	for it.Next() {
+		var i Item
+		if err := it.Decode(&i); err != nil {
+			t.Fatalf("Expected no error, got %#v", err)
+		}
+		ids = append(ids, i.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if fmt.Sprint(ids) != "[guid1 guid2 guid3]" {
+		t.Errorf("Expected all results of all pages, got %#v", ids)
+	}
+	if *fetched != 3 {
+		t.Errorf("Expected 3 pages to be fetched, got %d", *fetched)
+	}
+}
+
+func TestIterator_stopEarly(t *testing.T) {
+	ts, fetched := pagedServer([]string{`{"ID":"guid1"},{"ID":"guid2"}`, `{"ID":"guid3"}`})
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	seen := 0
+	err := cl.Iterate(context.Background(), "/api/v1/1/logistics/Items").Each(func(raw json.RawMessage) error {
+		seen++
+		return ErrStopIteration
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if seen != 1 {
+		t.Errorf("Expected 1 result to be seen, got %d", seen)
+	}
+	if *fetched != 1 {
+		t.Errorf("Expected only the first page to be fetched, got %d", *fetched)
+	}
+}
+
+func TestIterator_HTTPError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.transport.RetryWait = time.Millisecond
+	it := cl.Iterate(context.Background(), "/api/v1/1/logistics/Items")
+	if it.Next() {
+		t.Errorf("Expected Next to return false")
+	}
+	if _, ok := it.Err().(httperror.HTTPError); !ok {
+		t.Errorf("Expected HTTPError, got %#v", it.Err())
+	}
+}
+
+func TestIterator_All(t *testing.T) {
+	ts, _ := pagedServer([]string{`{"ID":"guid1"}`, `{"ID":"guid2"}`})
+	defer ts.Close()
+
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	out := []Item{}
+	if err := cl.Iterate(context.Background(), "/api/v1/1/logistics/Items").All(&out); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if len(out) != 2 || out[1].ID != "guid2" {
+		t.Errorf("Expected 2 items, got %#v", out)
+	}
+	if err := cl.Iterate(context.Background(), "").All(out); err == nil {
+		t.Errorf("Expected error when not passing a pointer to a slice")
+	}
+}
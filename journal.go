@@ -1,19 +1,30 @@
 package exactonline
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 
 	"github.com/Recras/exactonline/httperror"
 )
 
 type Journal struct {
-	ID          string
+	ID          string `json:",omitempty"`
 	Code        string
 	Description string
+	Type        int `json:",omitempty"`
 }
+
+const (
+	JournalTypeCash     = 10
+	JournalTypeBank     = 12
+	JournalTypeSales    = 20
+	JournalTypePurchase = 22
+	JournalTypeGeneral  = 90
+)
+
 type journals struct {
 	D struct {
 		Results []Journal `json:"results"`
@@ -24,14 +35,12 @@ const journalURI = "/api/v1/%d/financial/Journals"
 
 var ErrJournalNotFound = errors.New("Journal not found")
 
-func (c *Client) FindDefaultJournal() (Journal, error) {
-	if c.Division == 0 {
+func (c *Client) FindDefaultJournal(ctx context.Context) (Journal, error) {
+	if c.division == 0 {
 		return Journal{}, ErrNoDivision
 	}
-	u := fmt.Sprintf(journalURI, c.Division)
-	filt := url.Values{}
-	filt.Set("$filter", fmt.Sprintf("Code eq '%s'", "recras"))
-	resp, err := c.Client.Get(fmt.Sprintf("%s?%s", u, filt.Encode()))
+	u := fmt.Sprintf(journalURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(Eq("Code", "recras")).URL(u))
 	if err != nil {
 		return Journal{}, err
 	}
@@ -51,3 +60,53 @@ func (c *Client) FindDefaultJournal() (Journal, error) {
 	}
 	return out.D.Results[0], nil
 }
+
+var (
+	ErrJournalCodeRequired        = errors.New("Field `Code` on type `Journal` is mandatory")
+	ErrJournalDescriptionRequired = errors.New("Field `Description` on type `Journal` is mandatory")
+	ErrJournalTypeRequired        = errors.New("Field `Type` on type `Journal` is mandatory")
+)
+
+func (j *Journal) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if j.Code == "" {
+		return ErrJournalCodeRequired
+	}
+	if j.Description == "" {
+		return ErrJournalDescriptionRequired
+	}
+	if j.Type == 0 {
+		return ErrJournalTypeRequired
+	}
+
+	bs, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(journalURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]Journal{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*j = envelope["d"]
+	return nil
+}
+
+func (j *Journal) Update(ctx context.Context, c *Client, orig Journal) error {
+	return c.update(ctx, journalURI, j.ID, orig, *j, "ID")
+}
+
+func (j *Journal) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, journalURI, j.ID)
+}
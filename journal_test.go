@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -26,10 +27,10 @@ func TestFindDefaultJournal_Found(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	j, err := cl.FindDefaultJournal()
+	cl.division = 1234
+	j, err := cl.FindDefaultJournal(context.Background())
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -60,10 +61,10 @@ func TestFindDefaultJournal_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	_, err := cl.FindDefaultJournal()
+	cl.division = 1234
+	_, err := cl.FindDefaultJournal(context.Background())
 	if !apiCalled {
 		t.Errorf("Expected Journals API to be called")
 	}
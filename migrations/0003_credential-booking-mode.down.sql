@@ -0,0 +1 @@
+ALTER TABLE credential DROP COLUMN booking_mode;
@@ -0,0 +1 @@
+ALTER TABLE credential ADD COLUMN booking_mode TEXT NOT NULL DEFAULT 'salesentry';
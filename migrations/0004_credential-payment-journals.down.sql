@@ -0,0 +1,2 @@
+ALTER TABLE credential DROP COLUMN cash_journal;
+ALTER TABLE credential DROP COLUMN bank_journal;
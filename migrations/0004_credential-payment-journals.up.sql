@@ -0,0 +1,2 @@
+ALTER TABLE credential ADD COLUMN bank_journal TEXT NOT NULL DEFAULT '';
+ALTER TABLE credential ADD COLUMN cash_journal TEXT NOT NULL DEFAULT '';
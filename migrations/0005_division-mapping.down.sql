@@ -0,0 +1 @@
+DROP TABLE division_mapping;
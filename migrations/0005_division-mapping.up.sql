@@ -0,0 +1,6 @@
+CREATE TABLE division_mapping (
+	recras_hostname TEXT NOT NULL REFERENCES credential (recras_hostname) ON DELETE CASCADE,
+	bedrijf_id INTEGER NOT NULL,
+	division INTEGER NOT NULL,
+	PRIMARY KEY (recras_hostname, bedrijf_id)
+);
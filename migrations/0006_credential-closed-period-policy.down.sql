@@ -0,0 +1 @@
+ALTER TABLE credential DROP COLUMN closed_period_policy;
@@ -0,0 +1 @@
+ALTER TABLE credential ADD COLUMN closed_period_policy TEXT NOT NULL DEFAULT 'skip';
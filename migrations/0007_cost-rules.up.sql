@@ -0,0 +1,9 @@
+CREATE TABLE cost_rule (
+	id SERIAL PRIMARY KEY,
+	recras_hostname TEXT NOT NULL REFERENCES credential (recras_hostname) ON DELETE CASCADE,
+	product_id INTEGER NOT NULL DEFAULT 0,
+	productgroep_id INTEGER NOT NULL DEFAULT 0,
+	locatie_id INTEGER NOT NULL DEFAULT 0,
+	cost_center TEXT NOT NULL DEFAULT '',
+	cost_unit TEXT NOT NULL DEFAULT ''
+);
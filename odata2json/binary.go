@@ -1,6 +1,9 @@
 package odata2json
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"encoding/json"
+)
 
 type Binary []byte
 
@@ -13,3 +16,21 @@ func (b *Binary) MarshalJSON() ([]byte, error) {
 	}
 	return []byte(`"` + base64.StdEncoding.EncodeToString(*b) + `"`), nil
 }
+
+// UnmarshalJSON decodes a base64 string; null leaves b nil
+func (b *Binary) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == nil {
+		*b = nil
+		return nil
+	}
+	bs, err := base64.StdEncoding.DecodeString(*s)
+	if err != nil {
+		return err
+	}
+	*b = Binary(bs)
+	return nil
+}
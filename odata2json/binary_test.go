@@ -36,3 +36,28 @@ func TestBinaryMarshalJSON_bytes(t *testing.T) {
 		t.Errorf("Expected b64(hello\\n) to be %#v, got %#v (%#v)", `"aGVsbG8K"`, j, string(j))
 	}
 }
+
+func TestBinaryUnmarshalJSON(t *testing.T) {
+	var b Binary
+	if err := b.UnmarshalJSON([]byte(`"aGVsbG8K"`)); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if string(b) != "hello\n" {
+		t.Errorf("Expected bytes to be decoded, got %#v", string(b))
+	}
+
+	if err := b.UnmarshalJSON([]byte(`null`)); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if b != nil {
+		t.Errorf("Expected null to give nil, got %#v", b)
+	}
+
+	if err := b.UnmarshalJSON([]byte(`""`)); err != nil || b == nil || len(b) != 0 {
+		t.Errorf("Expected empty string to give empty Binary, got %#v, %#v", b, err)
+	}
+
+	if err := b.UnmarshalJSON([]byte(`"not base64!"`)); err == nil {
+		t.Errorf("Expected an error for invalid base64")
+	}
+}
@@ -3,41 +3,181 @@ package odata2json
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"regexp"
+	"strconv"
 	"time"
+
+	// Exact Online works in Dutch time, which must not depend on the
+	// timezone database of the host
+	_ "time/tzdata"
 )
 
 var ErrUnmarshalDate = errors.New("Error unmarshaling date")
-var odataDateRegex = regexp.MustCompile(`^\/Date\(([0-9]+)([+-][0-9]+)?\)\/$`)
+var odataDateRegex = regexp.MustCompile(`^\/Date\((-?[0-9]+)([+-][0-9]+)?\)\/$`)
+
+const (
+	marshalFormat         = "\"2006-01-02\""
+	marshalDateTimeFormat = "\"2006-01-02T15:04:05.999999999\""
+)
+
+// Amsterdam is the timezone Exact Online keeps its times in
+var Amsterdam = mustLoadLocation("Europe/Amsterdam")
 
-const marshalFormat = "\"2006-01-02\""
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
 
+var isoLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parse reads an OData /Date(ms)/ or /Date(ms+offset)/ value or an ISO-8601
+// date or datetime. Without an offset Exact Online sends the Amsterdam wall
+// clock time as if it were UTC, with an offset ms is the UTC instant and the
+// offset is in minutes. ISO-8601 values without a zone are Amsterdam time.
+func parse(s string) (time.Time, error) {
+	if m := odataDateRegex.FindStringSubmatch(s); m != nil {
+		ms, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return time.Time{}, ErrUnmarshalDate
+		}
+		t := time.Unix(ms/1000, ms%1000*1e6)
+		if m[2] == "" {
+			u := t.UTC()
+			return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), u.Nanosecond(), Amsterdam), nil
+		}
+		offset, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, ErrUnmarshalDate
+		}
+		return t.In(time.FixedZone("", offset*60)), nil
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.ParseInLocation(layout, s, Amsterdam); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrUnmarshalDate
+}
+
+// unmarshal decodes b into t; null leaves t zero and sets valid to false
+func unmarshal(b []byte, t *time.Time, valid *bool) error {
+	var str *string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	if str == nil {
+		*t = time.Time{}
+		*valid = false
+		return nil
+	}
+	parsed, err := parse(*str)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	*valid = true
+	return nil
+}
+
+// dateOf returns midnight UTC of the date t falls on in Amsterdam
+func dateOf(t time.Time) time.Time {
+	a := t.In(Amsterdam)
+	return time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Date is a calendar date, held as midnight UTC. It marshals as yyyy-mm-dd.
 type Date struct {
 	Time time.Time
 }
 
 func (d *Date) UnmarshalJSON(b []byte) error {
-	var str string
-	err := json.Unmarshal(b, &str)
-	if err != nil {
+	var valid bool
+	if err := unmarshal(b, &d.Time, &valid); err != nil {
 		return err
 	}
-	matches := odataDateRegex.FindStringSubmatch(str)
-	if len(matches) <= 1 {
-		return ErrUnmarshalDate
+	if valid {
+		d.Time = dateOf(d.Time)
 	}
-	var minutes, millis int64
+	return nil
+}
 
-	fmt.Sscan(string(matches[1]), &millis)
-	if len(matches) == 3 {
-		fmt.Sscan(string(matches[2]), &minutes)
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(d.Time.Format(marshalFormat)), nil
+}
+
+// DateTime is a point in time. It marshals as Amsterdam wall clock time
+// without a zone, the way Exact Online expects it, so during the hour the
+// clocks go back a time can not be told apart from the hour after it.
+type DateTime struct {
+	Time time.Time
+}
+
+func (d *DateTime) UnmarshalJSON(b []byte) error {
+	var valid bool
+	if err := unmarshal(b, &d.Time, &valid); err != nil {
+		return err
+	}
+	if valid {
+		d.Time = d.Time.In(Amsterdam)
+	}
+	return nil
+}
+
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return []byte(d.Time.In(Amsterdam).Format(marshalDateTimeFormat)), nil
+}
+
+// NullDate is a Date that can be null, which it is unless Valid is set
+type NullDate struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (d *NullDate) UnmarshalJSON(b []byte) error {
+	if err := unmarshal(b, &d.Time, &d.Valid); err != nil {
+		return err
+	}
+	if d.Valid {
+		d.Time = dateOf(d.Time)
 	}
+	return nil
+}
+
+func (d NullDate) MarshalJSON() ([]byte, error) {
+	if !d.Valid {
+		return []byte(`null`), nil
+	}
+	return Date{d.Time}.MarshalJSON()
+}
 
-	d.Time = time.Unix(millis/1000+minutes*60, millis%1000*1e6)
+// NullDateTime is a DateTime that can be null, which it is unless Valid is
+// set
+type NullDateTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (d *NullDateTime) UnmarshalJSON(b []byte) error {
+	if err := unmarshal(b, &d.Time, &d.Valid); err != nil {
+		return err
+	}
+	if d.Valid {
+		d.Time = d.Time.In(Amsterdam)
+	}
 	return nil
 }
 
-func (d *Date) MarshalJSON() ([]byte, error) {
-	return []byte(d.Time.Format(marshalFormat)), nil
+func (d NullDateTime) MarshalJSON() ([]byte, error) {
+	if !d.Valid {
+		return []byte(`null`), nil
+	}
+	return DateTime{d.Time}.MarshalJSON()
 }
@@ -1,6 +1,7 @@
 package odata2json
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -18,26 +19,38 @@ func TestMarshalJSON(t *testing.T) {
 
 func TestUnmarshalJSON_NoOffset(t *testing.T) {
 	d := &Date{}
-	if err := d.UnmarshalJSON([]byte(`"\/Date(1234)\/"`)); err != nil {
+	if err := d.UnmarshalJSON([]byte(`"\/Date(1420156800000)\/"`)); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
-	} else if d.Time != time.Unix(1, 234e6) {
-		t.Errorf("Expected time to be %#v, got %#v", time.Unix(1, 234e6), d.Time)
+	} else if d.Time != time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected date to be 2015-01-02, got %s", d.Time)
 	}
 }
+
 func TestUnmarshalJSON_PostiveOffset(t *testing.T) {
-	d := &Date{}
-	if err := d.UnmarshalJSON([]byte(`"\/Date(1234+1)\/"`)); err != nil {
+	d := &DateTime{}
+	if err := d.UnmarshalJSON([]byte(`"\/Date(1234+60)\/"`)); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
-	} else if d.Time != time.Unix(61, 234e6) {
-		t.Errorf("Expected time to be %#v, got %#v", time.Unix(61, 234e6), d.Time)
+	} else if !d.Time.Equal(time.Unix(1, 234e6)) {
+		t.Errorf("Expected the offset not to change the instant %s, got %s", time.Unix(1, 234e6), d.Time)
 	}
 }
+
 func TestUnmarshalJSON_NegativeOffset(t *testing.T) {
 	d := &Date{}
-	if err := d.UnmarshalJSON([]byte(`"\/Date(61234-1)\/"`)); err != nil {
+	// 23:00 on 1 January at -02:00 is already 2 January in Amsterdam
+	if err := d.UnmarshalJSON([]byte(`"\/Date(90000000-120)\/"`)); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
-	} else if d.Time != time.Unix(1, 234e6) {
-		t.Errorf("Expected time to be %#v, got %#v", time.Unix(1, 234e6), d.Time)
+	} else if d.Time != time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected date to be 1970-01-02, got %s", d.Time)
+	}
+}
+
+func TestUnmarshalJSON_Negative(t *testing.T) {
+	d := &DateTime{}
+	if err := d.UnmarshalJSON([]byte(`"\/Date(-86400000)\/"`)); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	} else if d.Time != time.Date(1969, 12, 31, 0, 0, 0, 0, Amsterdam) {
+		t.Errorf("Expected time to be 1969-12-31 in Amsterdam, got %s", d.Time)
 	}
 }
 
@@ -50,3 +63,93 @@ func TestUnmarshalJSONIncorrectFormat(t *testing.T) {
 		t.Errorf("Expected an error if `)/` is missing")
 	}
 }
+
+func TestDateTime_AmsterdamWallClock(t *testing.T) {
+	// Exact Online sends 14:30 Amsterdam time as 14:30 UTC
+	d := &DateTime{}
+	if err := d.UnmarshalJSON([]byte(`"\/Date(1436538600000)\/"`)); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	expected := time.Date(2015, 7, 10, 14, 30, 0, 0, Amsterdam)
+	if !d.Time.Equal(expected) {
+		t.Errorf("Expected %s, got %s", expected, d.Time)
+	}
+	b, _ := d.MarshalJSON()
+	if string(b) != `"2015-07-10T14:30:00"` {
+		t.Errorf("Expected Amsterdam wall clock time, got %s", b)
+	}
+}
+
+func TestDateTime_RoundTrip(t *testing.T) {
+	inputs := []string{
+		`"\/Date(1436538600123)\/"`,
+		`"\/Date(1436531400123+0)\/"`,
+		`"2015-07-10T14:30:00.123"`,
+		`"2015-07-10T12:30:00.123Z"`,
+		`"2015-07-10T14:30:00.123+02:00"`,
+	}
+	expected := time.Date(2015, 7, 10, 14, 30, 0, 123e6, Amsterdam)
+	for _, in := range inputs {
+		var d DateTime
+		if err := json.Unmarshal([]byte(in), &d); err != nil {
+			t.Errorf("Expected no error for %s, got %#v", in, err)
+			continue
+		}
+		if !d.Time.Equal(expected) {
+			t.Errorf("Expected %s for %s, got %s", expected, in, d.Time)
+		}
+		b, _ := json.Marshal(d)
+		var again DateTime
+		if err := json.Unmarshal(b, &again); err != nil || !again.Time.Equal(d.Time) {
+			t.Errorf("Expected %s to round-trip through %s, got %s, %#v", in, b, again.Time, err)
+		}
+	}
+}
+
+func TestDate_ISO(t *testing.T) {
+	var d Date
+	if err := json.Unmarshal([]byte(`"2015-01-02"`), &d); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if d.Time != time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected date to be 2015-01-02, got %s", d.Time)
+	}
+}
+
+func TestNullDate(t *testing.T) {
+	v := struct {
+		Date     NullDate
+		DateTime NullDateTime
+	}{}
+	if err := json.Unmarshal([]byte(`{"Date":null,"DateTime":null}`), &v); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if v.Date.Valid || v.DateTime.Valid {
+		t.Errorf("Expected null to be invalid, got %#v", v)
+	}
+	b, _ := json.Marshal(v)
+	if string(b) != `{"Date":null,"DateTime":null}` {
+		t.Errorf("Expected null to marshal to null, got %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Date":"\/Date(1420156800000)\/","DateTime":"2015-01-02T10:00:00"}`), &v); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if !v.Date.Valid || v.Date.Time != time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected valid date, got %#v", v.Date)
+	}
+	b, _ = json.Marshal(v)
+	if string(b) != `{"Date":"2015-01-02","DateTime":"2015-01-02T10:00:00"}` {
+		t.Errorf("Unexpected json %s", b)
+	}
+}
+
+func TestDate_ValueMarshal(t *testing.T) {
+	b, err := json.Marshal(map[string]Date{"d": {time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if string(b) != `{"d":"2015-01-02"}` {
+		t.Errorf("Expected Date values to marshal as dates, got %s", b)
+	}
+}
@@ -1,10 +1,11 @@
 package exactonline
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 
 	"github.com/Recras/exactonline/httperror"
 )
@@ -13,6 +14,7 @@ type PaymentCondition struct {
 	ID          string `json:",omitempty"`
 	Code        string
 	Description string
+	PaymentDays int `json:",omitempty"`
 }
 type paymentConditions struct {
 	D struct {
@@ -24,12 +26,22 @@ var ErrPaymentConditionNotFound = errors.New("PaymentCondition with description
 
 const paymentConditionURI = "/api/v1/%d/cashflow/PaymentConditions"
 
-func (cl *Client) FindPaymentConditionByDescription(desc string) (PaymentCondition, error) {
-	filt := url.Values{}
-	filt.Set("$filter", fmt.Sprintf("Description eq '"+desc+"'"))
-	u := fmt.Sprintf(paymentConditionURI+"?%s", cl.Division, filt.Encode())
+// GetPaymentConditions returns all payment conditions of the division
+func (c *Client) GetPaymentConditions(ctx context.Context) ([]PaymentCondition, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []PaymentCondition{}
+	if err := c.Iterate(ctx, fmt.Sprintf(paymentConditionURI, c.division)).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (cl *Client) FindPaymentConditionByDescription(ctx context.Context, desc string) (PaymentCondition, error) {
+	u := NewQuery().Filter(Eq("Description", desc)).URL(fmt.Sprintf(paymentConditionURI, cl.division))
 
-	resp, err := cl.Client.Get(u)
+	resp, err := cl.get(ctx, u)
 	if err != nil {
 		return PaymentCondition{}, err
 	}
@@ -49,3 +61,49 @@ func (cl *Client) FindPaymentConditionByDescription(desc string) (PaymentConditi
 	}
 	return pc.D.Results[0], nil
 }
+
+var (
+	ErrPaymentConditionCodeRequired        = errors.New("Field `Code` on type `PaymentCondition` is mandatory")
+	ErrPaymentConditionDescriptionRequired = errors.New("Field `Description` on type `PaymentCondition` is mandatory")
+)
+
+func (p *PaymentCondition) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if p.Code == "" {
+		return ErrPaymentConditionCodeRequired
+	}
+	if p.Description == "" {
+		return ErrPaymentConditionDescriptionRequired
+	}
+
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(paymentConditionURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]PaymentCondition{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*p = envelope["d"]
+	return nil
+}
+
+func (p *PaymentCondition) Update(ctx context.Context, c *Client, orig PaymentCondition) error {
+	return c.update(ctx, paymentConditionURI, p.ID, orig, *p, "ID")
+}
+
+func (p *PaymentCondition) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, paymentConditionURI, p.ID)
+}
@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -25,10 +26,10 @@ func TestFindPaymentConditionByDescription_notFound(t *testing.T) {
 	defer ts.Close()
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	_, err := cl.FindPaymentConditionByDescription("test")
+	cl.division = 1234
+	_, err := cl.FindPaymentConditionByDescription(context.Background(), "test")
 	if err != ErrPaymentConditionNotFound {
 		t.Errorf("Expected ErrPaymentConditionNotFound, got %#v", err)
 	}
@@ -51,10 +52,10 @@ func TestFindPaymentConditionByDescription_found(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	pc, err := cl.FindPaymentConditionByDescription("test")
+	cl.division = 1234
+	pc, err := cl.FindPaymentConditionByDescription(context.Background(), "test")
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
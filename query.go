@@ -0,0 +1,252 @@
+package exactonline
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GUID is an Exact Online identifier. Values of this type are formatted as
+// guid'...' literals when used in a Filter.
+type GUID string
+
+const literalDateTimeFormat = "2006-01-02T15:04:05"
+
+// Filter is an OData $filter expression. Build it with Eq, Ne, Gt, Lt,
+// SubstringOf, StartsWith, And and Or, so values are always escaped.
+type Filter string
+
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// ErrUnsupportedLiteral is returned by Literal for values it can't format
+type ErrUnsupportedLiteral struct {
+	Type reflect.Type
+}
+
+func (e ErrUnsupportedLiteral) Error() string {
+	return fmt.Sprintf("exactonline: unsupported filter literal of type %v", e.Type)
+}
+
+// Literal formats v as an OData literal. Supported are GUID, time.Time and
+// values with a string, bool, integer or float kind, also behind pointers; nil
+// is formatted as null.
+func Literal(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case GUID:
+		return "guid" + quote(string(v)), nil
+	case time.Time:
+		return "datetime'" + v.Format(literalDateTimeFormat) + "'", nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Invalid:
+		return "null", nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "null", nil
+		}
+		return Literal(rv.Elem().Interface())
+	case reflect.String:
+		return quote(rv.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	}
+	return "", ErrUnsupportedLiteral{Type: reflect.TypeOf(v)}
+}
+
+// compare formats a comparison of field with v. A v that Literal can't format
+// ends up as an invalid expression, so the request fails with the API's error
+// instead of silently matching the wrong rows.
+func compare(field, op string, v interface{}) Filter {
+	l, err := Literal(v)
+	if err != nil {
+		l = "<" + err.Error() + ">"
+	}
+	return Filter(field + " " + op + " " + l)
+}
+
+// Eq filters on field being equal to v
+func Eq(field string, v interface{}) Filter {
+	return compare(field, "eq", v)
+}
+
+// Ne filters on field not being equal to v
+func Ne(field string, v interface{}) Filter {
+	return compare(field, "ne", v)
+}
+
+// Gt filters on field being greater than v
+func Gt(field string, v interface{}) Filter {
+	return compare(field, "gt", v)
+}
+
+// Lt filters on field being less than v
+func Lt(field string, v interface{}) Filter {
+	return compare(field, "lt", v)
+}
+
+// Ge filters on field being greater than or equal to v
+func Ge(field string, v interface{}) Filter {
+	return compare(field, "ge", v)
+}
+
+// Le filters on field being less than or equal to v
+func Le(field string, v interface{}) Filter {
+	return compare(field, "le", v)
+}
+
+// SubstringOf filters on field containing s
+func SubstringOf(s, field string) Filter {
+	return Filter("substringof(" + quote(s) + ", " + field + ") eq true")
+}
+
+// StartsWith filters on field starting with s
+func StartsWith(field, s string) Filter {
+	return Filter("startswith(" + field + ", " + quote(s) + ") eq true")
+}
+
+func join(op string, fs []Filter) Filter {
+	parts := make([]string, 0, len(fs))
+	for _, f := range fs {
+		if f != "" {
+			parts = append(parts, string(f))
+		}
+	}
+	if len(parts) == 1 {
+		return Filter(parts[0])
+	}
+	for i := range parts {
+		parts[i] = "(" + parts[i] + ")"
+	}
+	return Filter(strings.Join(parts, " "+op+" "))
+}
+
+// And combines filters that all have to match
+func And(fs ...Filter) Filter {
+	return join("and", fs)
+}
+
+// Or combines filters of which at least one has to match
+func Or(fs ...Filter) Filter {
+	return join("or", fs)
+}
+
+// Query holds the OData query options for a request to the Exact Online API
+type Query struct {
+	filter      Filter
+	selects     []string
+	orderBy     []string
+	expand      []string
+	top         int
+	skip        int
+	inlineCount bool
+}
+
+// NewQuery creates an empty Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Filter sets the $filter option, replacing any previous filter
+func (q *Query) Filter(f Filter) *Query {
+	q.filter = f
+	return q
+}
+
+// Select adds fields to the $select option
+func (q *Query) Select(fields ...string) *Query {
+	q.selects = append(q.selects, fields...)
+	return q
+}
+
+// OrderBy adds an ascending sort on field to the $orderby option
+func (q *Query) OrderBy(field string) *Query {
+	q.orderBy = append(q.orderBy, field)
+	return q
+}
+
+// OrderByDesc adds a descending sort on field to the $orderby option
+func (q *Query) OrderByDesc(field string) *Query {
+	q.orderBy = append(q.orderBy, field+" desc")
+	return q
+}
+
+// Expand adds navigation properties to the $expand option
+func (q *Query) Expand(fields ...string) *Query {
+	q.expand = append(q.expand, fields...)
+	return q
+}
+
+// Top sets the $top option
+func (q *Query) Top(n int) *Query {
+	q.top = n
+	return q
+}
+
+// Skip sets the $skip option
+func (q *Query) Skip(n int) *Query {
+	q.skip = n
+	return q
+}
+
+// InlineCount requests the total number of results with $inlinecount=allpages
+func (q *Query) InlineCount() *Query {
+	q.inlineCount = true
+	return q
+}
+
+// Values returns the query options as url.Values
+func (q *Query) Values() url.Values {
+	v := url.Values{}
+	if q == nil {
+		return v
+	}
+	if q.filter != "" {
+		v.Set("$filter", string(q.filter))
+	}
+	if len(q.selects) > 0 {
+		v.Set("$select", strings.Join(q.selects, ","))
+	}
+	if len(q.orderBy) > 0 {
+		v.Set("$orderby", strings.Join(q.orderBy, ","))
+	}
+	if len(q.expand) > 0 {
+		v.Set("$expand", strings.Join(q.expand, ","))
+	}
+	if q.top > 0 {
+		v.Set("$top", strconv.Itoa(q.top))
+	}
+	if q.skip > 0 {
+		v.Set("$skip", strconv.Itoa(q.skip))
+	}
+	if q.inlineCount {
+		v.Set("$inlinecount", "allpages")
+	}
+	return v
+}
+
+// Encode returns the query options in URL encoded form
+func (q *Query) Encode() string {
+	return q.Values().Encode()
+}
+
+// URL appends the encoded query options to the path u
+func (q *Query) URL(u string) string {
+	enc := q.Encode()
+	if enc == "" {
+		return u
+	}
+	return u + "?" + enc
+}
@@ -0,0 +1,149 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type (
+	code   string
+	amount float64
+	flag   bool
+)
+
+func TestLiteral(t *testing.T) {
+	name, n := "recras", 12
+	cases := []struct {
+		in  interface{}
+		out string
+	}{
+		{"recras", `'recras'`},
+		{"O'Brien's", `'O''Brien''s'`},
+		{GUID("2dea6a1d-2d8e-4a1c-8c0e-9b3f7a4e5d6c"), `guid'2dea6a1d-2d8e-4a1c-8c0e-9b3f7a4e5d6c'`},
+		{GUID("x' or 1 eq 1 or 'a"), `guid'x'' or 1 eq 1 or ''a'`},
+		{time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC), `datetime'2015-01-02T03:04:05'`},
+		{12, `12`},
+		{int64(-3), `-3`},
+		{int8(-8), `-8`},
+		{int16(16), `16`},
+		{uint(7), `7`},
+		{uint8(255), `255`},
+		{uint64(1 << 63), `9223372036854775808`},
+		{1.5, `1.5`},
+		{float32(0.1), `0.1`},
+		{true, `true`},
+		{code("O'Brien"), `'O''Brien'`},
+		{amount(2.25), `2.25`},
+		{flag(false), `false`},
+		{&name, `'recras'`},
+		{&n, `12`},
+		{(*string)(nil), `null`},
+		{nil, `null`},
+	}
+	for _, c := range cases {
+		if l, err := Literal(c.in); err != nil || l != c.out {
+			t.Errorf("Expected literal of %#v to be %s, got %s (%v)", c.in, c.out, l, err)
+		}
+	}
+}
+
+func TestLiteral_unsupported(t *testing.T) {
+	for _, v := range []interface{}{struct{}{}, []string{"a"}, &[]int{1}} {
+		if _, err := Literal(v); err == nil {
+			t.Errorf("Expected error for literal of %#v", v)
+		} else if _, ok := err.(ErrUnsupportedLiteral); !ok {
+			t.Errorf("Expected ErrUnsupportedLiteral, got %#v", err)
+		}
+	}
+	if f := Eq("Code", struct{}{}); !strings.HasPrefix(string(f), "Code eq <") {
+		t.Errorf("Expected unsupported value to give an invalid filter, got %s", f)
+	}
+}
+
+func TestFilterOperators(t *testing.T) {
+	cases := []struct {
+		f   Filter
+		out string
+	}{
+		{Eq("Code", "recras"), `Code eq 'recras'`},
+		{Ne("Code", "recras"), `Code ne 'recras'`},
+		{Gt("Timestamp", 10), `Timestamp gt 10`},
+		{Lt("Amount", 2.5), `Amount lt 2.5`},
+		{Ge("EntryNumber", 5), `EntryNumber ge 5`},
+		{Le("EntryNumber", 7), `EntryNumber le 7`},
+		{SubstringOf("1-2'3", "Description"), `substringof('1-2''3', Description) eq true`},
+		{StartsWith("Code", "rec"), `startswith(Code, 'rec') eq true`},
+		{And(Eq("A", 1), Eq("B", 2)), `(A eq 1) and (B eq 2)`},
+		{Or(Eq("A", 1), And(Eq("B", 2), Eq("C", 3))), `(A eq 1) or ((B eq 2) and (C eq 3))`},
+		{And(Eq("A", 1)), `A eq 1`},
+		{And(Eq("A", 1), ""), `A eq 1`},
+		{Or(), ``},
+	}
+	for _, c := range cases {
+		if string(c.f) != c.out {
+			t.Errorf("Expected filter %s, got %s", c.out, c.f)
+		}
+	}
+}
+
+func TestQueryValues(t *testing.T) {
+	q := NewQuery().
+		Filter(Eq("Code", "recras")).
+		Select("ID", "Code").
+		OrderBy("Code").
+		OrderByDesc("Modified").
+		Expand("SalesEntryLines").
+		Top(10).
+		Skip(20).
+		InlineCount()
+	v := q.Values()
+	expected := map[string]string{
+		"$filter":      "Code eq 'recras'",
+		"$select":      "ID,Code",
+		"$orderby":     "Code,Modified desc",
+		"$expand":      "SalesEntryLines",
+		"$top":         "10",
+		"$skip":        "20",
+		"$inlinecount": "allpages",
+	}
+	for k, e := range expected {
+		if g := v.Get(k); g != e {
+			t.Errorf("Expected %s to be %#v, got %#v", k, e, g)
+		}
+	}
+	if len(v) != len(expected) {
+		t.Errorf("Expected %d options, got %#v", len(expected), v)
+	}
+}
+
+func TestQueryURL_empty(t *testing.T) {
+	if u := NewQuery().URL("/api/v1/1/crm/Accounts"); u != "/api/v1/1/crm/Accounts" {
+		t.Errorf("Expected URL without query string, got %#v", u)
+	}
+	var q *Query
+	if enc := q.Encode(); enc != "" {
+		t.Errorf("Expected nil Query to encode to empty string, got %#v", enc)
+	}
+}
+
+func TestFindDocumentTypeByDescription_Escaped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f := r.URL.Query().Get("$filter"); f != "Description eq 'Customer''s invoice'" {
+			t.Errorf("Expected quote in description to be escaped, got %#v", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+	cl.FindDocumentTypeByDescription(context.Background(), "Customer's invoice")
+}
@@ -0,0 +1,215 @@
+package exactonline
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is the API budget Exact Online reports for a division through the
+// X-RateLimit-* response headers
+type RateLimit struct {
+	DailyLimit     int
+	DailyRemaining int
+	DailyReset     time.Time
+
+	MinutelyLimit     int
+	MinutelyRemaining int
+	MinutelyReset     time.Time
+}
+
+// ErrDailyRateLimitExceeded is returned by the Transport instead of sending a
+// request once the daily budget of a division is used up
+type ErrDailyRateLimitExceeded struct {
+	Division int
+	Reset    time.Time
+}
+
+func (e ErrDailyRateLimitExceeded) Error() string {
+	return fmt.Sprintf("exactonline: daily rate limit of division %d exceeded until %s", e.Division, e.Reset.Format(time.RFC3339))
+}
+
+var divisionPathRegex = regexp.MustCompile(`^/api/v1/([0-9]+)/`)
+
+func divisionFromRequest(req *http.Request) int {
+	m := divisionPathRegex.FindStringSubmatch(req.URL.Path)
+	if m == nil {
+		return 0
+	}
+	d, _ := strconv.Atoi(m[1])
+	return d
+}
+
+type rateLimits struct {
+	sync.Mutex
+	divisions map[int]RateLimit
+	// next is the earliest time the last reserved request of a division
+	// may be sent, later requests are queued behind it
+	next map[int]time.Time
+}
+
+func headerInt(h http.Header, key string) (int, bool) {
+	v := h.Get(key)
+	if v == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(v)
+	return i, err == nil
+}
+
+func headerMillis(h http.Header, key string) (time.Time, bool) {
+	v := h.Get(key)
+	if v == "" {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(ms/1000, ms%1000*1e6), true
+}
+
+// update stores the limits of the response headers for division
+func (r *rateLimits) update(division int, h http.Header) {
+	r.Lock()
+	defer r.Unlock()
+	if r.divisions == nil {
+		r.divisions = make(map[int]RateLimit)
+	}
+	rl := r.divisions[division]
+	if v, ok := headerInt(h, "X-RateLimit-Limit"); ok {
+		rl.DailyLimit = v
+	}
+	if v, ok := headerInt(h, "X-RateLimit-Remaining"); ok {
+		rl.DailyRemaining = v
+	}
+	if v, ok := headerMillis(h, "X-RateLimit-Reset"); ok {
+		rl.DailyReset = v
+	}
+	if v, ok := headerInt(h, "X-RateLimit-Minutely-Limit"); ok {
+		rl.MinutelyLimit = v
+	}
+	if v, ok := headerInt(h, "X-RateLimit-Minutely-Remaining"); ok {
+		rl.MinutelyRemaining = v
+	}
+	if v, ok := headerMillis(h, "X-RateLimit-Minutely-Reset"); ok {
+		rl.MinutelyReset = v
+	}
+	r.divisions[division] = rl
+}
+
+// reserve claims a request from the budget of division. It returns how long
+// to wait before sending, or an error when the daily budget is exhausted.
+// Once the minutely budget is used up, requests are queued for the next
+// minute: they may not be sent before the requests reserved ahead of them.
+func (r *rateLimits) reserve(division int, now time.Time) (time.Duration, error) {
+	r.Lock()
+	defer r.Unlock()
+	rl, ok := r.divisions[division]
+	if !ok {
+		return 0, nil
+	}
+	if rl.DailyLimit > 0 && rl.DailyRemaining <= 0 && now.Before(rl.DailyReset) {
+		return 0, ErrDailyRateLimitExceeded{Division: division, Reset: rl.DailyReset}
+	}
+	if r.next == nil {
+		r.next = make(map[int]time.Time)
+	}
+	at := now
+	if next := r.next[division]; at.Before(next) {
+		at = next
+	}
+	if rl.MinutelyLimit > 0 && rl.MinutelyRemaining <= 0 && at.Before(rl.MinutelyReset) {
+		at = rl.MinutelyReset
+		rl.MinutelyRemaining = rl.MinutelyLimit
+		rl.MinutelyReset = rl.MinutelyReset.Add(time.Minute)
+	}
+	rl.MinutelyRemaining--
+	rl.DailyRemaining--
+	r.divisions[division] = rl
+	r.next[division] = at
+	return at.Sub(now), nil
+}
+
+func (r *rateLimits) get(division int) (RateLimit, bool) {
+	r.Lock()
+	defer r.Unlock()
+	rl, ok := r.divisions[division]
+	return rl, ok
+}
+
+func (r *rateLimits) all() map[int]RateLimit {
+	r.Lock()
+	defer r.Unlock()
+	out := make(map[int]RateLimit, len(r.divisions))
+	for d, rl := range r.divisions {
+		out[d] = rl
+	}
+	return out
+}
+
+// RateLimit returns the last known API budget of division
+func (t *Transport) RateLimit(division int) (RateLimit, bool) {
+	return t.limits.get(division)
+}
+
+// RateLimits returns the last known API budget of every division used
+func (t *Transport) RateLimits() map[int]RateLimit {
+	return t.limits.all()
+}
+
+// retryable tells whether a response with the given status may be retried. A
+// 429 response was never processed, so it is safe to retry any request; server
+// errors are only retried for idempotent methods.
+func retryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if status >= 500 {
+		return method != "POST" && method != "PATCH"
+	}
+	return false
+}
+
+// backoff returns the jittered wait before retry attempt n (starting at 0)
+func (t *Transport) backoff(n int) time.Duration {
+	d := t.RetryWait << uint(n)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func sleep(req *http.Request, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// RateLimit returns the last known API budget of the current division
+func (c *Client) RateLimit() (RateLimit, bool) {
+	if c.transport == nil {
+		return RateLimit{}, false
+	}
+	return c.transport.RateLimit(c.division)
+}
+
+// RateLimits returns the last known API budget of every division used
+func (c *Client) RateLimits() map[int]RateLimit {
+	if c.transport == nil {
+		return map[int]RateLimit{}
+	}
+	return c.transport.RateLimits()
+}
@@ -0,0 +1,231 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func millis(t time.Time) string {
+	return fmt.Sprintf("%d", t.UnixNano()/1e6)
+}
+
+func newRateLimitClient(ts *httptest.Server) *Client {
+	cl := Config{BaseURL: ts.URL}.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.transport.RetryWait = time.Millisecond
+	cl.division = 123
+	return cl
+}
+
+func TestRoundTrip_retryServerError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[]}}`)
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	resp, err := cl.Client.Get("/api/v1/123/logistics/Items")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected request to succeed after retries, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRoundTrip_giveUp(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	resp, err := cl.Client.Get("/api/v1/123/logistics/Items")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if resp.StatusCode != 500 {
+		t.Errorf("Expected last response to be returned, got %d", resp.StatusCode)
+	}
+	if calls != 4 {
+		t.Errorf("Expected 1 call and 3 retries, got %d calls", calls)
+	}
+}
+
+func TestRoundTrip_noRetryPostServerError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	cl.Client.Post("/api/v1/123/crm/Accounts", "application/json", bytes.NewBufferString(`{}`))
+	if calls != 1 {
+		t.Errorf("Expected POST not to be retried after a server error, got %d calls", calls)
+	}
+}
+
+func TestRoundTrip_retryPostTooManyRequests(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"Name":"asdf"}` {
+			t.Errorf("Expected body to be sent on every attempt, got %#v", string(body))
+		}
+		if calls == 1 {
+			w.WriteHeader(429)
+			return
+		}
+		w.WriteHeader(201)
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	resp, err := cl.Client.Post("/api/v1/123/crm/Accounts", "application/json", bytes.NewBufferString(`{"Name":"asdf"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if resp.StatusCode != 201 {
+		t.Errorf("Expected POST to be retried after 429, got %d", resp.StatusCode)
+	}
+}
+
+func TestRoundTrip_trackRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		w.Header().Set("X-RateLimit-Reset", millis(reset))
+		w.Header().Set("X-RateLimit-Minutely-Limit", "60")
+		w.Header().Set("X-RateLimit-Minutely-Remaining", "59")
+		w.Header().Set("X-RateLimit-Minutely-Reset", millis(reset))
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	if _, ok := cl.RateLimit(); ok {
+		t.Errorf("Expected no rate limit to be known before the first request")
+	}
+	cl.Client.Get("/api/v1/123/logistics/Items")
+	rl, ok := cl.RateLimit()
+	if !ok {
+		t.Fatalf("Expected rate limit to be known")
+	}
+	if rl.DailyLimit != 5000 || rl.DailyRemaining != 4000 || !rl.DailyReset.Equal(reset) {
+		t.Errorf("Expected daily limit to be tracked, got %#v", rl)
+	}
+	if rl.MinutelyLimit != 60 || rl.MinutelyRemaining != 59 || !rl.MinutelyReset.Equal(reset) {
+		t.Errorf("Expected minutely limit to be tracked, got %#v", rl)
+	}
+	if _, ok := cl.RateLimits()[456]; ok {
+		t.Errorf("Expected rate limits to be tracked per division")
+	}
+}
+
+func TestRoundTrip_waitForMinutelyReset(t *testing.T) {
+	calls := 0
+	reset := time.Now().Add(100 * time.Millisecond).Truncate(time.Millisecond)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Minutely-Limit", "60")
+		w.Header().Set("X-RateLimit-Minutely-Remaining", "0")
+		w.Header().Set("X-RateLimit-Minutely-Reset", millis(reset))
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	cl.Client.Get("/api/v1/123/logistics/Items")
+	cl.Client.Get("/api/v1/123/logistics/Items")
+	if time.Now().Before(reset) {
+		t.Errorf("Expected second request to wait for the minutely reset")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reset = time.Now().Add(time.Hour)
+	cl.Client.Get("/api/v1/123/logistics/Items")
+	req, _ := http.NewRequest("GET", "/api/v1/123/logistics/Items", nil)
+	if _, err := cl.Client.Do(req.WithContext(ctx)); err == nil {
+		t.Errorf("Expected cancelled context to stop waiting")
+	}
+}
+
+func TestReserve_queueConcurrent(t *testing.T) {
+	now := time.Now()
+	reset := now.Add(100 * time.Millisecond)
+	r := &rateLimits{divisions: map[int]RateLimit{
+		123: {MinutelyLimit: 3, MinutelyRemaining: 0, MinutelyReset: reset},
+	}}
+
+	var mu sync.Mutex
+	waits := map[time.Duration]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 7; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait, err := r.reserve(123, now)
+			if err != nil {
+				t.Errorf("Expected no error, got %#v", err)
+			}
+			mu.Lock()
+			waits[wait]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	first := reset.Sub(now)
+	if waits[first] != 3 || waits[first+time.Minute] != 3 || waits[first+2*time.Minute] != 1 {
+		t.Errorf("Expected 3 requests per minute after the reset, got %v", waits)
+	}
+}
+
+func TestRoundTrip_dailyLimitExceeded(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", millis(time.Now().Add(time.Hour)))
+	}))
+	defer ts.Close()
+
+	cl := newRateLimitClient(ts)
+	cl.Client.Get("/api/v1/123/logistics/Items")
+	_, err := cl.Client.Get("/api/v1/123/logistics/Items")
+	if err == nil {
+		t.Errorf("Expected error when daily limit is exceeded")
+	}
+	if calls != 1 {
+		t.Errorf("Expected request not to be sent when daily limit is exceeded, got %d calls", calls)
+	}
+	if _, err := cl.Client.Get("/api/v1/456/logistics/Items"); err != nil {
+		t.Errorf("Expected other division not to be limited, got %#v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const (
+	receivablesListURI = "/api/v1/%d/read/financial/ReceivablesList"
+	receivablesURI     = "/api/v1/%d/cashflow/Receivables"
+)
+
+// ReceivablesListItem is an outstanding item from the ReceivablesList.
+// Amount is what is still to be paid; items that are fully paid are not
+// listed.
+type ReceivablesListItem struct {
+	HID             int64
+	AccountID       string `json:"AccountId"`
+	AccountCode     string
+	AccountName     string
+	Amount          float64
+	AmountInTransit float64
+	CurrencyCode    string
+	Description     string
+	DueDate         odata2json.Date
+	EntryNumber     int
+	InvoiceDate     odata2json.Date
+	InvoiceNumber   int
+	JournalCode     string
+	YourRef         string
+}
+
+// Receivable is a term to be received on a booked invoice. PaymentReference
+// and YourRef are copied from the SalesEntry or SalesInvoice it belongs to.
+type Receivable struct {
+	ID               string
+	Account          string
+	AmountDC         float64
+	Description      string
+	DueDate          odata2json.Date
+	EntryNumber      int
+	InvoiceDate      odata2json.Date
+	InvoiceNumber    int
+	IsFullyPaid      bool
+	PaymentReference string
+	YourRef          string
+	Status           int
+}
+
+// GetReceivablesList returns the outstanding items matching f, or all
+// outstanding items when f is empty
+func (c *Client) GetReceivablesList(ctx context.Context, f Filter) ([]ReceivablesListItem, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []ReceivablesListItem{}
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(receivablesListURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetReceivables returns the receivables matching f, or all receivables when
+// f is empty
+func (c *Client) GetReceivables(ctx context.Context, f Filter) ([]Receivable, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Receivable{}
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(receivablesURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReceivableStatus sums up the receivables booked for a single reference,
+// usually a Recras factuurnummer
+type ReceivableStatus struct {
+	Reference   string
+	Account     string
+	Amount      float64
+	OpenAmount  float64
+	DueDate     odata2json.Date
+	IsFullyPaid bool
+}
+
+type ErrReceivableNotFound struct {
+	Division  int
+	Reference string
+}
+
+func (e ErrReceivableNotFound) Error() string {
+	return fmt.Sprintf("Receivable not found for reference `%s` in Division %d", e.Reference, e.Division)
+}
+
+// receivableKeys returns the references r can be looked up by
+func receivableKeys(r Receivable) []string {
+	keys := []string{}
+	if r.PaymentReference != "" {
+		keys = append(keys, r.PaymentReference)
+	}
+	if r.YourRef != "" && r.YourRef != r.PaymentReference {
+		keys = append(keys, r.YourRef)
+	}
+	return keys
+}
+
+// receivableStatuses groups receivables by reference. open holds the open
+// amount per EntryNumber, which is counted once per reference even when an
+// entry has multiple terms.
+func receivableStatuses(receivables []Receivable, open map[int]float64) map[string]ReceivableStatus {
+	out := map[string]ReceivableStatus{}
+	entries := map[string]map[int]bool{}
+	for _, r := range receivables {
+		for _, key := range receivableKeys(r) {
+			s, ok := out[key]
+			if !ok {
+				s = ReceivableStatus{Reference: key, Account: r.Account, IsFullyPaid: true}
+				entries[key] = map[int]bool{}
+			}
+			s.Amount += r.AmountDC
+			s.IsFullyPaid = s.IsFullyPaid && r.IsFullyPaid
+			if !r.IsFullyPaid && !entries[key][r.EntryNumber] {
+				s.OpenAmount += open[r.EntryNumber]
+				entries[key][r.EntryNumber] = true
+			}
+			if !r.IsFullyPaid && (s.DueDate.Time.IsZero() || r.DueDate.Time.Before(s.DueDate.Time)) {
+				s.DueDate = r.DueDate
+			}
+			out[key] = s
+		}
+	}
+	return out
+}
+
+func openAmounts(items []ReceivablesListItem) map[int]float64 {
+	open := map[int]float64{}
+	for _, i := range items {
+		open[i.EntryNumber] += i.Amount
+	}
+	return open
+}
+
+// GetOpenReceivables returns the status of every reference that is not fully
+// paid, keyed by PaymentReference and by YourRef. A synced invoice that is
+// missing from the result has been paid.
+func (c *Client) GetOpenReceivables(ctx context.Context) (map[string]ReceivableStatus, error) {
+	items, err := c.GetReceivablesList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	receivables, err := c.GetReceivables(ctx, Eq("IsFullyPaid", false))
+	if err != nil {
+		return nil, err
+	}
+	return receivableStatuses(receivables, openAmounts(items)), nil
+}
+
+// FindReceivableStatus returns the status of the receivables with ref as
+// their PaymentReference or YourRef
+func (c *Client) FindReceivableStatus(ctx context.Context, ref string) (ReceivableStatus, error) {
+	receivables, err := c.GetReceivables(ctx, Or(Eq("PaymentReference", ref), Eq("YourRef", ref)))
+	if err != nil {
+		return ReceivableStatus{}, err
+	}
+	if len(receivables) == 0 {
+		return ReceivableStatus{}, ErrReceivableNotFound{c.division, ref}
+	}
+
+	entryFilters := []Filter{}
+	for _, r := range receivables {
+		if !r.IsFullyPaid {
+			entryFilters = append(entryFilters, Eq("EntryNumber", r.EntryNumber))
+		}
+	}
+	open := map[int]float64{}
+	if len(entryFilters) > 0 {
+		items, err := c.GetReceivablesList(ctx, Or(entryFilters...))
+		if err != nil {
+			return ReceivableStatus{}, err
+		}
+		open = openAmounts(items)
+	}
+	return receivableStatuses(receivables, open)[ref], nil
+}
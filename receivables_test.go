@@ -0,0 +1,94 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFindReceivableStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("$filter")
+		switch r.URL.Path {
+		case "/api/v1/123/cashflow/Receivables":
+			switch filter {
+			case "(PaymentReference eq '1-2-3') or (YourRef eq '1-2-3')":
+				fmt.Fprint(w, `{"d":{"results":[
+					{"ID":"r1","Account":"account-guid","AmountDC":50,"DueDate":"/Date(1405036800000)/","EntryNumber":7,"IsFullyPaid":true,"PaymentReference":"1-2-3"},
+					{"ID":"r2","Account":"account-guid","AmountDC":50,"DueDate":"/Date(1407715200000)/","EntryNumber":7,"IsFullyPaid":false,"PaymentReference":"1-2-3"}
+				]}}`)
+			case "(PaymentReference eq '4-5-6') or (YourRef eq '4-5-6')":
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"r3","AmountDC":10,"DueDate":"/Date(1405036800000)/","EntryNumber":8,"IsFullyPaid":true,"PaymentReference":"4-5-6"}]}}`)
+			default:
+				fmt.Fprint(w, `{"d":{"results":[]}}`)
+			}
+		case "/api/v1/123/read/financial/ReceivablesList":
+			if filter != "EntryNumber eq 7" {
+				t.Errorf("Unexpected filter %#v", filter)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"HID":1,"Amount":30,"DueDate":"/Date(1407715200000)/","EntryNumber":7}]}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	s, err := cl.FindReceivableStatus(context.Background(), "1-2-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if s.IsFullyPaid || s.Amount != 100 || s.OpenAmount != 30 {
+		t.Errorf("Expected 30 of 100 to be open, got %#v", s)
+	}
+	if s.DueDate.Time.Unix() != 1407715200 {
+		t.Errorf("Expected DueDate of the open term, got %s", s.DueDate.Time)
+	}
+
+	s, err = cl.FindReceivableStatus(context.Background(), "4-5-6")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if !s.IsFullyPaid || s.OpenAmount != 0 {
+		t.Errorf("Expected paid receivable, got %#v", s)
+	}
+
+	if _, err := cl.FindReceivableStatus(context.Background(), "7-8-9"); err != (ErrReceivableNotFound{123, "7-8-9"}) {
+		t.Errorf("Expected ErrReceivableNotFound, got %#v", err)
+	}
+}
+
+func TestGetOpenReceivables(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/cashflow/Receivables":
+			if f := r.URL.Query().Get("$filter"); f != "IsFullyPaid eq false" {
+				t.Errorf("Unexpected filter %#v", f)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"r1","AmountDC":50,"DueDate":"/Date(1405036800000)/","EntryNumber":7,"PaymentReference":"1-2-3","YourRef":"PO-1"}]}}`)
+		case "/api/v1/123/read/financial/ReceivablesList":
+			fmt.Fprint(w, `{"d":{"results":[{"HID":1,"Amount":50,"EntryNumber":7,"DueDate":"/Date(1405036800000)/","InvoiceDate":"/Date(1405036800000)/"}]}}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	open, err := cl.GetOpenReceivables(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if open["1-2-3"].OpenAmount != 50 || open["PO-1"].OpenAmount != 50 {
+		t.Errorf("Expected receivable to be keyed by PaymentReference and YourRef, got %#v", open)
+	}
+}
@@ -1,6 +1,9 @@
 package recras
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 type Bedrijf struct {
 	ID           int    `json:"id"`
@@ -8,12 +11,12 @@ type Bedrijf struct {
 	BTWNummer    string `json:"btw_nummer"`
 }
 
-func (c *Client) GetBedrijven(f url.Values) ([]Bedrijf, error) {
+func (c *Client) GetBedrijven(ctx context.Context, f url.Values) ([]Bedrijf, error) {
 	if f == nil {
 		f = url.Values{}
 	}
 	url := "/api2/bedrijven?" + f.Encode()
 	out := []Bedrijf{}
-	err := c.Get(url, &out)
+	err := c.Get(ctx, url, &out)
 	return out, err
 }
@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -13,7 +14,7 @@ func TestGetBedrijven(t *testing.T) {
 	})
 	defer ts.Close()
 
-	bs, err := c.GetBedrijven(nil)
+	bs, err := c.GetBedrijven(context.Background(), nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -0,0 +1,33 @@
+package recras
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	BetaalmethodeContant     = "contant"
+	BetaalmethodePin         = "pin"
+	BetaalmethodeIDeal       = "ideal"
+	BetaalmethodeOverboeking = "overboeking"
+)
+
+type Betaling struct {
+	ID            int     `json:"id"`
+	FactuurID     int     `json:"factuur_id"`
+	Bedrag        float64 `json:"bedrag"`
+	Betaalmethode string  `json:"betaalmethode"`
+	Datum         Date    `json:"datum"`
+	Omschrijving  string  `json:"omschrijving"`
+}
+
+// IsContant tells whether b was paid in cash rather than through the bank
+func (b Betaling) IsContant() bool {
+	return b.Betaalmethode == BetaalmethodeContant
+}
+
+func (c *Client) GetBetalingen(ctx context.Context, factuurID int) ([]Betaling, error) {
+	out := []Betaling{}
+	err := c.Get(ctx, fmt.Sprintf("/api2/facturen/%d/betalingen", factuurID), &out)
+	return out, err
+}
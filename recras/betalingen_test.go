@@ -0,0 +1,39 @@
+package recras
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGetBetalingen(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/facturen/12/betalingen" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id": 3, "factuur_id": 12, "bedrag": 25.5, "betaalmethode": "contant", "datum": "2015-01-02", "omschrijving": "Aanbetaling"}]`)
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	c := &Client{Client: http.Client{
+		Transport: &Transport{
+			BaseURL: u,
+		},
+	}}
+
+	bs, err := c.GetBetalingen(context.Background(), 12)
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(bs) != 1 {
+		t.Fatalf("Expected 1 Betaling, got %d", len(bs))
+	}
+	if bs[0].Bedrag != 25.5 || !bs[0].IsContant() || bs[0].Datum.Time != time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("Unexpected Betaling %#v", bs[0])
+	}
+}
@@ -2,6 +2,7 @@ package recras
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -78,11 +79,15 @@ func NewClient(hostname, username, password string) Client {
 	}}}
 }
 
-func (c *Client) Get(u string, item interface{}) error {
+func (c *Client) Get(ctx context.Context, u string, item interface{}) error {
 	log.WithFields(log.Fields{
 		"url": u,
 	}).Debug("Recras client GET")
-	resp, err := c.Client.Get(u)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -95,7 +100,7 @@ func (c *Client) Get(u string, item interface{}) error {
 	return err
 }
 
-func (c *Client) Post(u string, item interface{}) error {
+func (c *Client) Post(ctx context.Context, u string, item interface{}) error {
 	log.WithFields(log.Fields{
 		"url":  u,
 		"item": item,
@@ -104,8 +109,12 @@ func (c *Client) Post(u string, item interface{}) error {
 	if err != nil {
 		return err
 	}
-	bb := bytes.NewReader(payload)
-	resp, err := c.Client.Post(u, "application/json", bb)
+	req, err := http.NewRequestWithContext(ctx, "POST", u, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
 	if err != nil {
 		return err
 	}
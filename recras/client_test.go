@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -120,7 +121,7 @@ func TestGet(t *testing.T) {
 	defer ts.Close()
 
 	i := map[string]interface{}{}
-	err := c.Get(apiURL, &i)
+	err := c.Get(context.Background(), apiURL, &i)
 	if err != nil {
 		t.Fatalf("Expected no error, got %#v", err)
 	}
@@ -138,7 +139,7 @@ func TestGet_StatusCodeNotOK(t *testing.T) {
 	})
 	defer ts.Close()
 
-	err := c.Get("", nil)
+	err := c.Get(context.Background(), "", nil)
 	if _, ok := err.(httperror.HTTPError); !ok {
 		t.Fatalf("Expected HTTPError, got %#v", err)
 	}
@@ -150,12 +151,25 @@ func TestGet_InvalidJSON(t *testing.T) {
 	})
 	defer ts.Close()
 
-	err := c.Get("", nil)
+	err := c.Get(context.Background(), "", nil)
 	if err == nil {
 		t.Fatalf("Expected error")
 	}
 }
 
+func TestGet_Cancelled(t *testing.T) {
+	ts, c := createTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request to be sent")
+	})
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Get(ctx, "", nil); err == nil {
+		t.Fatalf("Expected error for cancelled context")
+	}
+}
+
 func TestPost(t *testing.T) {
 	apiCalled := false
 	ts, c := createTestAPI(func(w http.ResponseWriter, r *http.Request) {
@@ -183,7 +197,7 @@ func TestPost(t *testing.T) {
 	item := map[string]interface{}{
 		"test": "asdf",
 	}
-	err := c.Post("", &item)
+	err := c.Post(context.Background(), "", &item)
 	if !apiCalled {
 		t.Fatalf("Expected API to be called")
 	}
@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"errors"
 	"time"
 )
@@ -27,9 +28,9 @@ var (
 	ErrNoSoort = errors.New("recras.Contactmoment.Soort cannot be empty")
 )
 
-func (c *Contactmoment) Save(client *Client) error {
+func (c *Contactmoment) Save(ctx context.Context, client *Client) error {
 	if c.Soort == "" {
 		return ErrNoSoort
 	}
-	return client.Post("/api2/contactmomenten", c)
+	return client.Post(ctx, "/api2/contactmomenten", c)
 }
@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -66,7 +67,7 @@ func TestSaveContactmoment(t *testing.T) {
 	defer ts.Close()
 	c := newContactmoment()
 	c.ID = 0
-	err := c.Save(client)
+	err := c.Save(context.Background(), client)
 	if !apiCalled {
 		t.Errorf("Expected API to be called")
 	}
@@ -81,7 +82,7 @@ func TestSaveContactmoment(t *testing.T) {
 func TestSaveContactmoment_NoSoort(t *testing.T) {
 	c := newContactmoment()
 	c.Soort = ""
-	err := c.Save(nil)
+	err := c.Save(context.Background(), nil)
 	if err != ErrNoSoort {
 		t.Errorf("Expected ErrNoSoort, got %#v", err)
 	}
@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"net/url"
 	"time"
 )
@@ -28,17 +29,19 @@ const (
 )
 
 type Factuurregel struct {
-	ID                   int            `json:"id"`
-	Naam                 string         `json:"naam"`
-	Type                 string         `json:"type"`
-	Kortingspercentage   float64        `json:"kortingspercentage"`
-	Kortingsomschrijving string         `json":kortingomschrijving"`
-	Aantal               int            `json:"aantal"`
-	Bedrag               float64        `json:"bedrag"`
-	BTWPercentage        float64        `json:"btw_percentage"`
-	ProductID            int            `json:"product_id"`
-	BoekingsregelID      int            `json:"boekingsregel_id"`
-	Regels               []Factuurregel `json:"regels"`
+	ID                   int     `json:"id"`
+	Naam                 string  `json:"naam"`
+	Type                 string  `json:"type"`
+	Kortingspercentage   float64 `json:"kortingspercentage"`
+	Kortingsomschrijving string  `json:"kortingsomschrijving"`
+	Aantal               int     `json:"aantal"`
+	Bedrag               float64 `json:"bedrag"`
+	BTWPercentage        float64 `json:"btw_percentage"`
+	ProductID            int     `json:"product_id"`
+	BoekingsregelID      int     `json:"boekingsregel_id"`
+	// LocatieID is the location of the booking the regel is for, if any
+	LocatieID int            `json:"locatie_id"`
+	Regels    []Factuurregel `json:"regels"`
 }
 
 type Factuur struct {
@@ -54,14 +57,16 @@ type Factuur struct {
 	Klant                              Klant          `json:"Klant,omitempty"`
 	PdfLocatie                         string         `json:"pdf_locatie,omitempty"`
 	CalculatedTotaalbedragInclusiefBTW float64        `json:"calculated_totaalbedrag_inclusief_btw"`
+	// Valuta is the ISO code of the currency of the amounts, euro when empty
+	Valuta string `json:"valuta,omitempty"`
 }
 
-func (c *Client) GetFacturenFilter(f url.Values) ([]Factuur, error) {
+func (c *Client) GetFacturenFilter(ctx context.Context, f url.Values) ([]Factuur, error) {
 	if f == nil {
 		f = url.Values{}
 	}
 	out := []Factuur{}
 	url := "/api2/facturen?regelsformat=exactonline&" + f.Encode()
-	err := c.Get(url, &out)
+	err := c.Get(ctx, url, &out)
 	return out, err
 }
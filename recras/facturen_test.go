@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -23,7 +24,7 @@ func TestGetAllFacturenWithFilters(t *testing.T) {
 		},
 	}}
 
-	fs, err := c.GetFacturenFilter(nil)
+	fs, err := c.GetFacturenFilter(context.Background(), nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
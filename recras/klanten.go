@@ -1,9 +1,16 @@
 package recras
 
 type Klant struct {
-	ID          int    `json:"id"`
-	Displaynaam string `json:"displaynaam"`
-	Adres       string `json:"adres"`
-	Postcode    string `json:"postcode"`
-	Plaats      string `json:"plaats"`
+	ID           int    `json:"id"`
+	Displaynaam  string `json:"displaynaam"`
+	Voornaam     string `json:"voornaam"`
+	Achternaam   string `json:"achternaam"`
+	Bedrijfsnaam string `json:"bedrijfsnaam"`
+	Email        string `json:"email1"`
+	Telefoon     string `json:"telefoon1"`
+	Website      string `json:"website"`
+	Adres        string `json:"adres"`
+	Postcode     string `json:"postcode"`
+	Plaats       string `json:"plaats"`
+	Landcode     string `json:"landcode"`
 }
@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"strconv"
 )
 
@@ -19,15 +20,15 @@ type Gebruiker struct {
 	Rollen []Rol `json:"rollen,omitempty"`
 }
 
-func (c *Client) GetCurrentPersoneel() (Personeel, error) {
+func (c *Client) GetCurrentPersoneel(ctx context.Context) (Personeel, error) {
 	p := Personeel{}
-	err := c.Get("/api2/personeel/me", &p)
+	err := c.Get(ctx, "/api2/personeel/me", &p)
 	return p, err
 }
 
-func (c *Client) GetGebruiker(p Personeel) (Gebruiker, error) {
+func (c *Client) GetGebruiker(ctx context.Context, p Personeel) (Gebruiker, error) {
 	g := Gebruiker{}
-	err := c.Get("/api2/gebruikers/"+strconv.Itoa(p.ID)+"?embed=rollen", &g)
+	err := c.Get(ctx, "/api2/gebruikers/"+strconv.Itoa(p.ID)+"?embed=rollen", &g)
 	return g, err
 }
 
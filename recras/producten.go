@@ -1,13 +1,31 @@
 package recras
 
+import "context"
+
 type Product struct {
 	ID            int    `json:"id"`
 	LeverancierID int    `json:"leverancier_id"`
 	Naam          string `json:"naam"`
+	// Verkoop is the sales price including VAT
+	Verkoop        float64 `json:"verkoop"`
+	BTWPercentage  float64 `json:"btw_percentage"`
+	Eenheid        string  `json:"eenheid"`
+	ProductgroepID int     `json:"productgroep_id"`
+}
+
+type Productgroep struct {
+	ID   int    `json:"id"`
+	Naam string `json:"naam"`
 }
 
-func (c *Client) GetAllProducten() ([]Product, error) {
+func (c *Client) GetAllProducten(ctx context.Context) ([]Product, error) {
 	out := []Product{}
-	err := c.Get("/api2/producten", &out)
+	err := c.Get(ctx, "/api2/producten", &out)
+	return out, err
+}
+
+func (c *Client) GetProductgroepen(ctx context.Context) ([]Productgroep, error) {
+	out := []Productgroep{}
+	err := c.Get(ctx, "/api2/productgroepen", &out)
 	return out, err
 }
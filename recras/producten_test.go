@@ -1,6 +1,7 @@
 package recras
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -13,3 +14,21 @@ func TestGetAllProducten(t *testing.T) {
 	defer ts.Close()
 	_ = c
 }
+
+func TestGetProductgroepen(t *testing.T) {
+	ts, c := createTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/productgroepen" {
+			t.Errorf("Expected path to be `/api2/productgroepen`, got `%s`", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id": 3, "naam": "Arrangementen"}]`)
+	})
+	defer ts.Close()
+
+	pgs, err := c.GetProductgroepen(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(pgs) != 1 || pgs[0] != (Productgroep{ID: 3, Naam: "Arrangementen"}) {
+		t.Errorf("Unexpected productgroepen %#v", pgs)
+	}
+}
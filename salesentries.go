@@ -2,10 +2,10 @@ package exactonline
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
 
 	"github.com/Recras/exactonline/httperror"
 	"github.com/Recras/exactonline/odata2json"
@@ -20,7 +20,8 @@ type SalesEntry struct {
 	ID               string `json:"EntryID,omitempty"`
 	Customer         string
 	Description      string
-	DueDate          odata2json.Date
+	EntryNumber      int `json:",omitempty"`
+	DueDate          odata2json.NullDate
 	EntryDate        odata2json.Date
 	Journal          string
 	PaymentCondition string
@@ -29,6 +30,15 @@ type SalesEntry struct {
 	Document         string                  `json:",omitempty"`
 	DeferredSELines  deferredSalesEntryLines `json:"SalesEntryLines"`
 	Type             int32                   `json:",omitempty"`
+
+	// Currency is the currency of the FC amounts, the division currency
+	// when empty. Without a Rate Exact Online uses its own exchange rate.
+	Currency string  `json:",omitempty"`
+	Rate     float64 `json:",omitempty"`
+	// AmountFC and AmountDC are the totals Exact Online calculated, in
+	// Currency and in the division currency
+	AmountFC float64 `json:",omitempty"`
+	AmountDC float64 `json:",omitempty"`
 }
 
 const (
@@ -65,11 +75,14 @@ func (se *SalesEntry) SetSalesEntryLines(lines []SalesEntryLine) {
 type SalesEntryLine struct {
 	ID          string `json:",omitempty"`
 	AmountFC    float64
+	AmountDC    float64 `json:",omitempty"`
 	Description string
 	EntryID     string `json:",omitempty"`
 	GLAccount   string
 	Quantity    float64
 	VATCode     string
+	CostCenter  string `json:",omitempty"`
+	CostUnit    string `json:",omitempty"`
 }
 
 type ErrSalesEntryNotFound struct {
@@ -78,14 +91,15 @@ type ErrSalesEntryNotFound struct {
 }
 
 func (e ErrSalesEntryNotFound) Error() string {
-	return fmt.Sprintf("SalesEntry not found for FactuurNummer `%#v` in Division %s", e.Division, e.FactuurNummer)
+	return fmt.Sprintf("SalesEntry not found for `%s` in Division %d", e.FactuurNummer, e.Division)
 }
 
-func (c *Client) FindSalesEntry(factuurnr string) (SalesEntry, error) {
-	filt := url.Values{}
-	filt.Add("$filter", "substringof('"+factuurnr+"', Description) eq true")
-	u := fmt.Sprintf(salesEntriesURI, c.Division)
-	resp, err := c.Client.Get(u + "?" + filt.Encode())
+// FindSalesEntry finds an entry with factuurnr in its Description. It also
+// matches longer invoice numbers that start with factuurnr, use
+// FindSalesEntryByPaymentReference for entries the sync created.
+func (c *Client) FindSalesEntry(ctx context.Context, factuurnr string) (SalesEntry, error) {
+	u := fmt.Sprintf(salesEntriesURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(SubstringOf(factuurnr, "Description")).URL(u))
 	if err != nil {
 		return SalesEntry{}, err
 	}
@@ -102,19 +116,92 @@ func (c *Client) FindSalesEntry(factuurnr string) (SalesEntry, error) {
 	}
 
 	if len(out.D.Results) == 0 {
-		return SalesEntry{}, &ErrSalesEntryNotFound{c.Division, factuurnr}
+		return SalesEntry{}, &ErrSalesEntryNotFound{c.division, factuurnr}
 	}
 
 	return out.D.Results[0], nil
 }
 
+// GetSalesEntries returns the entries matching f with their SalesEntryLines
+func (c *Client) GetSalesEntries(ctx context.Context, f Filter) ([]SalesEntry, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []SalesEntry{}
+	u := NewQuery().Filter(f).Expand("SalesEntryLines").URL(fmt.Sprintf(salesEntriesURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// findSalesEntry returns the first entry matching f, or
+// ErrSalesEntryNotFound for key. Its lines are deferred, see
+// LoadSalesEntryLines, so checking whether an entry exists stays cheap.
+func (c *Client) findSalesEntry(ctx context.Context, f Filter, key string) (SalesEntry, error) {
+	if c.division == 0 {
+		return SalesEntry{}, ErrNoDivision
+	}
+	entries := []SalesEntry{}
+	u := NewQuery().Filter(f).Top(1).URL(fmt.Sprintf(salesEntriesURI, c.division))
+	if err := c.Iterate(ctx, u).All(&entries); err != nil {
+		return SalesEntry{}, err
+	}
+	if len(entries) == 0 {
+		return SalesEntry{}, &ErrSalesEntryNotFound{c.division, key}
+	}
+	return entries[0], nil
+}
+
+func (c *Client) FindSalesEntryByID(ctx context.Context, id string) (SalesEntry, error) {
+	return c.findSalesEntry(ctx, Eq("EntryID", GUID(id)), id)
+}
+
+func (c *Client) FindSalesEntryByEntryNumber(ctx context.Context, n int) (SalesEntry, error) {
+	return c.findSalesEntry(ctx, Eq("EntryNumber", n), fmt.Sprint(n))
+}
+
+func (c *Client) FindSalesEntryByPaymentReference(ctx context.Context, ref string) (SalesEntry, error) {
+	return c.findSalesEntry(ctx, Eq("PaymentReference", ref), ref)
+}
+
+func (c *Client) FindSalesEntryByYourRef(ctx context.Context, ref string) (SalesEntry, error) {
+	return c.findSalesEntry(ctx, Eq("YourRef", ref), ref)
+}
+
+// GetSalesEntryLines returns the lines of the entry with ID entryID
+func (c *Client) GetSalesEntryLines(ctx context.Context, entryID string) ([]SalesEntryLine, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []SalesEntryLine{}
+	u := NewQuery().Filter(Eq("EntryID", GUID(entryID))).URL(fmt.Sprintf(salesEntryLinesURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoadSalesEntryLines retrieves the lines of s when they were deferred
+func (s *SalesEntry) LoadSalesEntryLines(ctx context.Context, c *Client) error {
+	if s.DeferredSELines.SalesEntryLines != nil {
+		return nil
+	}
+	lines, err := c.GetSalesEntryLines(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+	s.SetSalesEntryLines(lines)
+	return nil
+}
+
 var (
 	ErrSalesEntryLinesRequired            = errors.New("Field SalesEntryLines is required on SalesEntry")
 	ErrSalesEntryCustomerRequired         = errors.New("Field Customer is required on SalesEntry")
 	ErrSalesEntryPaymentConditionRequired = errors.New("Field PaymentCondition is required on SalesEntry")
 )
 
-func (s *SalesEntry) Save(ecl *Client) error {
+func (s *SalesEntry) Save(ctx context.Context, ecl *Client) error {
 	if s.SalesEntryLines() == nil || len(s.SalesEntryLines()) == 0 {
 		return ErrSalesEntryLinesRequired
 	}
@@ -130,7 +217,7 @@ func (s *SalesEntry) Save(ecl *Client) error {
 		return err
 	}
 	bb := bytes.NewBuffer(bs)
-	resp, err := ecl.Client.Post(fmt.Sprintf(salesEntriesURI, ecl.Division), "application/json", bb)
+	resp, err := ecl.post(ctx, fmt.Sprintf(salesEntriesURI, ecl.division), bb)
 	if err != nil {
 		return err
 	}
@@ -150,3 +237,13 @@ func (s *SalesEntry) Save(ecl *Client) error {
 
 	return nil
 }
+
+// Update changes the fields of the entry itself that differ from orig, its
+// SalesEntryLines are not sent
+func (s *SalesEntry) Update(ctx context.Context, c *Client, orig SalesEntry) error {
+	return c.update(ctx, salesEntriesURI, s.ID, orig, *s, "EntryID", "SalesEntryLines")
+}
+
+func (s *SalesEntry) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, salesEntriesURI, s.ID)
+}
@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -54,29 +55,29 @@ func TestSaveSalesEntry(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
+	cl.division = 123
 
 	a := SalesEntry{}
-	if err := a.Save(cl); err != ErrSalesEntryLinesRequired {
+	if err := a.Save(context.Background(), cl); err != ErrSalesEntryLinesRequired {
 		t.Errorf("Expected ErrSalesEntryLinesRequired if SalesEntryLines is missing, got %#v", err)
 	}
 	a.SetSalesEntryLines([]SalesEntryLine{})
-	if err := a.Save(cl); err != ErrSalesEntryLinesRequired {
+	if err := a.Save(context.Background(), cl); err != ErrSalesEntryLinesRequired {
 		t.Errorf("Expected ErrSalesEntryLinesRequired if SalesEntryLines is empty, got %#v", err)
 	}
 	a.SetSalesEntryLines([]SalesEntryLine{{}})
-	if err := a.Save(cl); err != ErrSalesEntryCustomerRequired {
+	if err := a.Save(context.Background(), cl); err != ErrSalesEntryCustomerRequired {
 		t.Errorf("Expected ErrSalesEntryCustomerRequired if Customer is empty, got %#v", err)
 	}
 	a.Customer = "customer"
-	if err := a.Save(cl); err != ErrSalesEntryPaymentConditionRequired {
+	if err := a.Save(context.Background(), cl); err != ErrSalesEntryPaymentConditionRequired {
 		t.Errorf("Expecte ErrSalesEntryPaymentConditionRequired, if PaymentCondition is empty, got %#v", err)
 	}
 	a.PaymentCondition = "paymentcond"
 
-	if err := a.Save(cl); err != nil {
+	if err := a.Save(context.Background(), cl); err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
 	if !apiCalled {
@@ -102,10 +103,10 @@ func TestFindSalesEntry_NotFound(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	_, err := cl.FindSalesEntry("1-2-3")
+	cl.division = 1234
+	_, err := cl.FindSalesEntry(context.Background(), "1-2-3")
 	if !salesEntryCalled {
 		t.Errorf("Expected SalesEntries endpoint to be called")
 	}
@@ -130,10 +131,10 @@ func TestFindSalesEntry_Found(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	s, err := cl.FindSalesEntry("1-2-3")
+	cl.division = 1234
+	s, err := cl.FindSalesEntry(context.Background(), "1-2-3")
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -156,10 +157,10 @@ func TestFindSalesEntry_Found_deferredSEL(t *testing.T) {
 
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 1234
-	s, err := cl.FindSalesEntry("1-2-3")
+	cl.division = 1234
+	s, err := cl.FindSalesEntry(context.Background(), "1-2-3")
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -170,3 +171,81 @@ func TestFindSalesEntry_Found_deferredSEL(t *testing.T) {
 		t.Errorf("Expected deferred url to be %#v, got %#v", "/api/v1/1234/salesentry/SalesEntries(guid'guid')/SalesEntryLines", s.DeferredSELines.Deferred.URI)
 	}
 }
+
+func TestFindSalesEntryByPaymentReference(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/api/v1/1234/salesentry/SalesEntryLines" {
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"line","AmountFC":12.5,"GLAccount":"gl","VATCode":"R21"}]}}`)
+			return
+		}
+		if f := q.Get("$filter"); f != "PaymentReference eq '2019-1'" {
+			t.Errorf("Expected $filter query to be `PaymentReference eq '2019-1'`, got %#v", f)
+		}
+		if e := q.Get("$expand"); e != "" {
+			t.Errorf("Expected no lines to be expanded, got %#v", e)
+		}
+		if top := q.Get("$top"); top != "1" {
+			t.Errorf("Expected a single entry to be requested, got %#v", top)
+		}
+		if q.Get("$filter") == "PaymentReference eq '2019-1'" {
+			fmt.Fprint(w, `{"d":{"results":[{"EntryID":"guid","EntryNumber":15000001,"PaymentReference":"2019-1","EntryDate":"/Date(12345)/","DueDate":null,
+				"SalesEntryLines":{"__deferred":{"uri":"/api/v1/1234/salesentry/SalesEntries(guid'guid')/SalesEntryLines"}}}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+
+	s, err := cl.FindSalesEntryByPaymentReference(context.Background(), "2019-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if s.ID != "guid" || s.EntryNumber != 15000001 {
+		t.Errorf("Unexpected entry %#v", s)
+	}
+	if lines := s.SalesEntryLines(); len(lines) != 0 {
+		t.Errorf("Expected the lines to be deferred, got %#v", lines)
+	}
+	if err := s.LoadSalesEntryLines(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if lines := s.SalesEntryLines(); len(lines) != 1 || lines[0].AmountFC != 12.5 {
+		t.Errorf("Expected the loaded line, got %#v", lines)
+	}
+}
+
+func TestLoadSalesEntryLines(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/v1/1234/salesentry/SalesEntryLines" {
+			t.Errorf("Expected path to be `/api/v1/1234/salesentry/SalesEntryLines`, got %#v", r.URL.Path)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "EntryID eq guid'guid'" {
+			t.Errorf("Expected $filter on EntryID, got %#v", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"line1","EntryID":"guid","AmountFC":10},{"ID":"line2","EntryID":"guid","AmountFC":2.5}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 1234
+
+	s := SalesEntry{ID: "guid"}
+	s.DeferredSELines.Deferred.URI = "/api/v1/1234/salesentry/SalesEntries(guid'guid')/SalesEntryLines"
+	if err := s.LoadSalesEntryLines(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(s.SalesEntryLines()) != 2 {
+		t.Errorf("Expected 2 lines, got %#v", s.SalesEntryLines())
+	}
+	if err := s.LoadSalesEntryLines(context.Background(), cl); err != nil || calls != 1 {
+		t.Errorf("Expected loaded lines not to be retrieved again, got %d calls, %#v", calls, err)
+	}
+}
@@ -0,0 +1,222 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const (
+	salesInvoicesURI        = "/api/v1/%d/salesinvoice/SalesInvoices"
+	printedSalesInvoicesURI = "/api/v1/%d/salesinvoice/PrintedSalesInvoices"
+)
+
+// SalesInvoice is an invoice in the invoicing module of Exact Online. Unlike
+// a SalesEntry it is not booked until it is printed, see PrintedSalesInvoice.
+type SalesInvoice struct {
+	ID               string `json:"InvoiceID,omitempty"`
+	InvoiceNumber    int    `json:",omitempty"`
+	OrderedBy        string
+	InvoiceTo        string `json:",omitempty"`
+	Description      string
+	InvoiceDate      odata2json.Date
+	DueDate          odata2json.NullDate
+	Journal          string
+	PaymentCondition string
+	PaymentReference string                    `json:",omitempty"`
+	YourRef          string                    `json:",omitempty"`
+	Document         string                    `json:",omitempty"`
+	Status           int                       `json:",omitempty"`
+	Type             int                       `json:",omitempty"`
+	DeferredLines    deferredSalesInvoiceLines `json:"SalesInvoiceLines"`
+
+	// Currency is the currency of the invoice, the division currency when
+	// empty. AmountFC and AmountDC are the totals Exact Online calculated.
+	Currency string  `json:",omitempty"`
+	AmountFC float64 `json:",omitempty"`
+	AmountDC float64 `json:",omitempty"`
+}
+
+const (
+	SalesInvoiceStatusDraft     = 10
+	SalesInvoiceStatusOpen      = 20
+	SalesInvoiceStatusProcessed = 50
+
+	SalesInvoiceTypeInvoice    = 8020
+	SalesInvoiceTypeCreditNote = 8021
+)
+
+type salesInvoices struct {
+	D struct {
+		Results []SalesInvoice `json:"results"`
+	} `json:"d"`
+}
+
+type deferredSalesInvoiceLines struct {
+	Deferred struct {
+		URI string `json:"uri"`
+	} `json:"__deferred"`
+	SalesInvoiceLines []SalesInvoiceLine `json:"results,omitempty"`
+}
+
+func (d deferredSalesInvoiceLines) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.SalesInvoiceLines)
+}
+
+func (si SalesInvoice) SalesInvoiceLines() []SalesInvoiceLine {
+	return si.DeferredLines.SalesInvoiceLines
+}
+
+func (si *SalesInvoice) SetSalesInvoiceLines(lines []SalesInvoiceLine) {
+	si.DeferredLines.SalesInvoiceLines = lines
+}
+
+// SalesInvoiceLine is a line of a SalesInvoice. Discount is a fraction, 0.1
+// being 10% off UnitPrice.
+type SalesInvoiceLine struct {
+	ID          string `json:",omitempty"`
+	InvoiceID   string `json:",omitempty"`
+	Item        string
+	Description string
+	Quantity    float64
+	UnitPrice   float64
+	Discount    float64 `json:",omitempty"`
+	VATCode     string
+	GLAccount   string `json:",omitempty"`
+	CostCenter  string `json:",omitempty"`
+	CostUnit    string `json:",omitempty"`
+}
+
+type ErrSalesInvoiceNotFound struct {
+	Division      int
+	FactuurNummer string
+}
+
+func (e ErrSalesInvoiceNotFound) Error() string {
+	return fmt.Sprintf("SalesInvoice not found for FactuurNummer `%s` in Division %d", e.FactuurNummer, e.Division)
+}
+
+// FindSalesInvoice finds the invoice with factuurnr as its PaymentReference,
+// which is where the sync puts the factuurnummer
+func (c *Client) FindSalesInvoice(ctx context.Context, factuurnr string) (SalesInvoice, error) {
+	if c.division == 0 {
+		return SalesInvoice{}, ErrNoDivision
+	}
+	u := fmt.Sprintf(salesInvoicesURI, c.division)
+	resp, err := c.get(ctx, NewQuery().Filter(Eq("PaymentReference", factuurnr)).Top(1).URL(u))
+	if err != nil {
+		return SalesInvoice{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return SalesInvoice{}, httperror.New(resp)
+	}
+
+	out := &salesInvoices{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(out); err != nil {
+		return SalesInvoice{}, err
+	}
+	if len(out.D.Results) == 0 {
+		return SalesInvoice{}, ErrSalesInvoiceNotFound{c.division, factuurnr}
+	}
+	return out.D.Results[0], nil
+}
+
+var (
+	ErrSalesInvoiceLinesRequired     = errors.New("Field SalesInvoiceLines is required on SalesInvoice")
+	ErrSalesInvoiceOrderedByRequired = errors.New("Field OrderedBy is required on SalesInvoice")
+	ErrSalesInvoiceJournalRequired   = errors.New("Field Journal is required on SalesInvoice")
+)
+
+func (si *SalesInvoice) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if len(si.SalesInvoiceLines()) == 0 {
+		return ErrSalesInvoiceLinesRequired
+	}
+	if si.OrderedBy == "" {
+		return ErrSalesInvoiceOrderedByRequired
+	}
+	if si.Journal == "" {
+		return ErrSalesInvoiceJournalRequired
+	}
+
+	bs, err := json.Marshal(si)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(salesInvoicesURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]SalesInvoice{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*si = envelope["d"]
+	return nil
+}
+
+// Update changes the fields of a draft invoice that differ from orig, its
+// lines are not sent
+func (si *SalesInvoice) Update(ctx context.Context, c *Client, orig SalesInvoice) error {
+	return c.update(ctx, salesInvoicesURI, si.ID, orig, *si, "InvoiceID", "SalesInvoiceLines")
+}
+
+func (si *SalesInvoice) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, salesInvoicesURI, si.ID)
+}
+
+// PrintedSalesInvoice finalizes a draft SalesInvoice: saving it books the
+// invoice and assigns its InvoiceNumber
+type PrintedSalesInvoice struct {
+	InvoiceID           string
+	SendEmailToCustomer bool
+	SenderEmailAddress  string `json:",omitempty"`
+	Status              int    `json:",omitempty"`
+}
+
+var ErrPrintedSalesInvoiceIDRequired = errors.New("Field InvoiceID is required on PrintedSalesInvoice")
+
+func (p *PrintedSalesInvoice) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if p.InvoiceID == "" {
+		return ErrPrintedSalesInvoiceIDRequired
+	}
+
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(printedSalesInvoicesURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]PrintedSalesInvoice{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*p = envelope["d"]
+	return nil
+}
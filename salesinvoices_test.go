@@ -0,0 +1,102 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFindSalesInvoice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/salesinvoice/SalesInvoices" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		if r.URL.Query().Get("$top") != "1" {
+			t.Errorf("Expected a single invoice to be requested, got %#v", r.URL.Query())
+		}
+		if r.URL.Query().Get("$filter") == "PaymentReference eq '1-2-3'" {
+			fmt.Fprint(w, `{"d":{"results":[{"InvoiceID":"invoice-guid","Description":"Recras factuur: 1-2-3","Status":50}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	si, err := cl.FindSalesInvoice(context.Background(), "1-2-3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if si.ID != "invoice-guid" || si.Status != SalesInvoiceStatusProcessed {
+		t.Errorf("Unexpected invoice %#v", si)
+	}
+
+	_, err = cl.FindSalesInvoice(context.Background(), "4-5-6")
+	if _, ok := err.(ErrSalesInvoiceNotFound); !ok {
+		t.Errorf("Expected ErrSalesInvoiceNotFound, got %#v", err)
+	}
+}
+
+func TestSalesInvoiceSave(t *testing.T) {
+	var printed PrintedSalesInvoice
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/salesinvoice/SalesInvoices":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			lines, ok := body["SalesInvoiceLines"].([]interface{})
+			if !ok || len(lines) != 1 {
+				t.Errorf("Expected lines to be sent inline, got %#v", body["SalesInvoiceLines"])
+			}
+			if _, ok := body["InvoiceID"]; ok {
+				t.Errorf("Expected no InvoiceID to be sent, got %#v", body)
+			}
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"InvoiceID":"invoice-guid","OrderedBy":"account-guid","Journal":"recras","Status":10,"SalesInvoiceLines":{"__deferred":{"uri":"lines"}}}}`)
+		case "/api/v1/123/salesinvoice/PrintedSalesInvoices":
+			json.NewDecoder(r.Body).Decode(&printed)
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"InvoiceID":"invoice-guid","Status":50}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	si := SalesInvoice{OrderedBy: "account-guid", Journal: "recras"}
+	if err := si.Save(context.Background(), cl); err != ErrSalesInvoiceLinesRequired {
+		t.Errorf("Expected ErrSalesInvoiceLinesRequired, got %#v", err)
+	}
+	si.SetSalesInvoiceLines([]SalesInvoiceLine{{Item: "item-guid", Quantity: 2, UnitPrice: 5}})
+	if err := si.Save(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if si.ID != "invoice-guid" || si.Status != SalesInvoiceStatusDraft {
+		t.Errorf("Expected saved draft invoice, got %#v", si)
+	}
+
+	p := PrintedSalesInvoice{}
+	if err := p.Save(context.Background(), cl); err != ErrPrintedSalesInvoiceIDRequired {
+		t.Errorf("Expected ErrPrintedSalesInvoiceIDRequired, got %#v", err)
+	}
+	p.InvoiceID = si.ID
+	if err := p.Save(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if printed.InvoiceID != "invoice-guid" || p.Status != SalesInvoiceStatusProcessed {
+		t.Errorf("Expected invoice to be processed, got %#v", p)
+	}
+}
@@ -0,0 +1,88 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const salesItemPriceURI = "/api/v1/%d/logistics/SalesItemPrices"
+
+// SalesItemPrice is the price of an Item in Exact Online's own invoicing.
+// Without an Account it is the price for every customer.
+type SalesItemPrice struct {
+	ID                string `json:",omitempty"`
+	Item              string
+	ItemCode          string `json:",omitempty"`
+	Account           string `json:",omitempty"`
+	Currency          string `json:",omitempty"`
+	Price             float64
+	Unit              string `json:",omitempty"`
+	NumberOfItemsFrom float64
+	StartDate         odata2json.NullDate
+	EndDate           odata2json.NullDate
+}
+
+// GetSalesItemPrices returns the prices matching f, or all of them when f is
+// empty
+func (c *Client) GetSalesItemPrices(ctx context.Context, f Filter) ([]SalesItemPrice, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []SalesItemPrice{}
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(salesItemPriceURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindSalesItemPrices returns the prices of the Item with ID itemID
+func (c *Client) FindSalesItemPrices(ctx context.Context, itemID string) ([]SalesItemPrice, error) {
+	return c.GetSalesItemPrices(ctx, Eq("Item", GUID(itemID)))
+}
+
+var ErrSalesItemPriceItemRequired = errors.New("Field `Item` on type `SalesItemPrice` is mandatory")
+
+func (p *SalesItemPrice) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if p.Item == "" {
+		return ErrSalesItemPriceItemRequired
+	}
+
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(salesItemPriceURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]SalesItemPrice{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*p = envelope["d"]
+	return nil
+}
+
+func (p *SalesItemPrice) Update(ctx context.Context, c *Client, orig SalesItemPrice) error {
+	return c.update(ctx, salesItemPriceURI, p.ID, orig, *p, "ID")
+}
+
+func (p *SalesItemPrice) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, salesItemPriceURI, p.ID)
+}
@@ -0,0 +1,74 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFindSalesItemPrices(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/logistics/SalesItemPrices" {
+			t.Errorf("Expected path to be `/api/v1/123/logistics/SalesItemPrices`, got `%s`", p)
+		}
+		if f := r.URL.Query().Get("$filter"); f != "Item eq guid'item-guid'" {
+			t.Errorf("Expected $filter on Item, got `%s`", f)
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"price-guid","Item":"item-guid","Price":12.5,"Unit":"stuk","StartDate":null,"EndDate":null}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	prices, err := cl.FindSalesItemPrices(context.Background(), "item-guid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(prices) != 1 || prices[0].Price != 12.5 || prices[0].Unit != "stuk" || prices[0].StartDate.Valid {
+		t.Errorf("Unexpected prices %#v", prices)
+	}
+}
+
+func TestSaveSalesItemPrice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected method to be `POST`, got `%s`", r.Method)
+		}
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["Item"] != "item-guid" || payload["Price"] != 12.5 {
+			t.Errorf("Expected item and price in payload, got %#v", payload)
+		}
+		if payload["StartDate"] != nil {
+			t.Errorf("Expected StartDate to be null, got %#v", payload["StartDate"])
+		}
+		w.WriteHeader(201)
+		payload["ID"] = "price-guid"
+		json.NewEncoder(w).Encode(map[string]interface{}{"d": payload})
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	p := SalesItemPrice{Price: 12.5}
+	if err := p.Save(context.Background(), cl); err != ErrSalesItemPriceItemRequired {
+		t.Errorf("Expected ErrSalesItemPriceItemRequired, got %#v", err)
+	}
+	p.Item = "item-guid"
+	if err := p.Save(context.Background(), cl); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if p.ID != "price-guid" {
+		t.Errorf("Expected ID to be set, got %#v", p.ID)
+	}
+}
@@ -0,0 +1,206 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SetupReport lists what SetupAdministration created, in order
+type SetupReport struct {
+	Created []string
+}
+
+func (r *SetupReport) add(format string, args ...interface{}) {
+	r.Created = append(r.Created, fmt.Sprintf(format, args...))
+}
+
+// SetupAdministration creates what the sync needs in the current division
+// and is still missing: the recras sales journal, the recras payment
+// condition, a recras:<percentage> VAT code for each of percentages and the
+// recras unit. Existing setup is left alone, so running it again creates
+// nothing. On error the report holds what was created before it.
+func (c *Client) SetupAdministration(ctx context.Context, percentages []string) (SetupReport, error) {
+	report := SetupReport{}
+	if c.division == 0 {
+		return report, ErrNoDivision
+	}
+	steps := []func(context.Context, *SetupReport) error{
+		c.setupJournal,
+		c.setupPaymentCondition,
+		func(ctx context.Context, r *SetupReport) error {
+			return c.setupVATCodes(ctx, percentages, r)
+		},
+		c.setupUnit,
+	}
+	for _, step := range steps {
+		if err := step(ctx, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (c *Client) setupJournal(ctx context.Context, r *SetupReport) error {
+	_, err := c.FindDefaultJournal(ctx)
+	if err != ErrJournalNotFound {
+		return err
+	}
+	j := Journal{Code: "recras", Description: "Recras verkoopboek", Type: JournalTypeSales}
+	if err := j.Save(ctx, c); err != nil {
+		return err
+	}
+	r.add("Dagboek %s", j.Code)
+	return nil
+}
+
+func (c *Client) setupPaymentCondition(ctx context.Context, r *SetupReport) error {
+	_, err := c.FindPaymentConditionByDescription(ctx, "recras")
+	if err != ErrPaymentConditionNotFound {
+		return err
+	}
+	all, err := c.GetPaymentConditions(ctx)
+	if err != nil {
+		return err
+	}
+	taken := map[string]bool{}
+	for _, pc := range all {
+		taken[strings.ToUpper(pc.Code)] = true
+	}
+	code := freeCode(taken, "RC", "R", 2)
+	if code == "" {
+		return fmt.Errorf("no free payment condition code left for recras")
+	}
+	pc := PaymentCondition{Code: code, Description: "recras", PaymentDays: 14}
+	if err := pc.Save(ctx, c); err != nil {
+		return err
+	}
+	r.add("Betalingsconditie %s", pc.Description)
+	return nil
+}
+
+// recrasVATCode returns the preferred VAT code of at most the 3 characters
+// Exact Online allows, e.g. R21 for 21%. Percentages can share it, so
+// setupVATCodes falls back to another free code.
+func recrasVATCode(percentage string) string {
+	code := "R" + strings.Replace(percentage, ".", "", -1)
+	if len(code) > 3 {
+		code = code[:3]
+	}
+	return code
+}
+
+const codeDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// freeCode returns preferred when it is not taken, or else the first code of
+// size characters starting with prefix that is not taken. taken holds upper
+// case codes; "" is returned when all codes are taken.
+func freeCode(taken map[string]bool, preferred, prefix string, size int) string {
+	if !taken[strings.ToUpper(preferred)] {
+		return preferred
+	}
+	n := size - len(prefix)
+	total := int(math.Pow(float64(len(codeDigits)), float64(n)))
+	for i := 0; i < total; i++ {
+		suffix := make([]byte, n)
+		for j, k := n-1, i; j >= 0; j, k = j-1, k/len(codeDigits) {
+			suffix[j] = codeDigits[k%len(codeDigits)]
+		}
+		if code := prefix + string(suffix); !taken[code] {
+			return code
+		}
+	}
+	return ""
+}
+
+// recrasPercentage returns the percentage of a recras:<percentage> VAT code
+// the way the sync reads it
+func recrasPercentage(v VATCode) (float64, bool) {
+	var pct float64
+	n, _ := fmt.Sscanf(v.Description, "recras:%f", &pct)
+	return pct, n == 1
+}
+
+// setupVATCodes creates the missing recras VAT codes. Recras amounts include
+// VAT. The GL accounts are taken from an existing sales VAT code with the same
+// percentage, so VAT ends up where the bookkeeper expects it. Percentages that
+// already have a recras VAT code, also when written differently, are skipped.
+func (c *Client) setupVATCodes(ctx context.Context, percentages []string, r *SetupReport) error {
+	existing, err := c.GetRecrasVATCodes(ctx)
+	if err != nil {
+		return err
+	}
+	have := map[float64]bool{}
+	for _, v := range existing {
+		if pct, ok := recrasPercentage(v); ok {
+			have[pct] = true
+		}
+	}
+
+	var all []VATCode
+	taken := map[string]bool{}
+	for _, p := range percentages {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		var pct float64
+		if _, err := fmt.Sscan(p, &pct); err != nil {
+			return fmt.Errorf("invalid VAT percentage `%s`", p)
+		}
+		if have[pct] {
+			continue
+		}
+		if all == nil {
+			if all, err = c.GetVATCodes(ctx, ""); err != nil {
+				return err
+			}
+			for _, t := range all {
+				taken[strings.ToUpper(t.Code)] = true
+			}
+		}
+
+		code := freeCode(taken, recrasVATCode(p), "R", 3)
+		if code == "" {
+			return fmt.Errorf("no free VAT code left for recras:%s", p)
+		}
+		v := VATCode{
+			Code:               code,
+			Description:        "recras:" + p,
+			Percentage:         pct / 100,
+			Type:               VATCodeTypeInclusive,
+			VATTransactionType: VATTransactionTypeSales,
+		}
+		for _, t := range all {
+			if t.IsBlocked || t.VATTransactionType == VATTransactionTypePurchase {
+				continue
+			}
+			if math.Abs(t.Percentage-v.Percentage) < 1e-6 && t.GLToPay != "" {
+				v.GLToPay = t.GLToPay
+				v.GLToClaim = t.GLToClaim
+				break
+			}
+		}
+		if err := v.Save(ctx, c); err != nil {
+			return err
+		}
+		have[pct] = true
+		taken[strings.ToUpper(v.Code)] = true
+		r.add("BTW-code %s (%s)", v.Code, v.Description)
+	}
+	return nil
+}
+
+func (c *Client) setupUnit(ctx context.Context, r *SetupReport) error {
+	_, err := c.FindUnitByCode(ctx, "recras")
+	if _, ok := err.(ErrUnitNotFound); !ok {
+		return err
+	}
+	u := Unit{Code: "recras", Description: "Recras", Type: UnitTypeOther, Active: true}
+	if err := u.Save(ctx, c); err != nil {
+		return err
+	}
+	r.add("Eenheid %s", u.Code)
+	return nil
+}
@@ -0,0 +1,164 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSetupAdministration(t *testing.T) {
+	created := map[string]map[string]interface{}{}
+	empty := `{"d":{"results":[]}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			created[r.URL.Path] = body
+			body["ID"] = "new-guid"
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{"d": body})
+			return
+		}
+		_, done := created[r.URL.Path]
+		switch r.URL.Path {
+		case "/api/v1/123/financial/Journals":
+			if done {
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"j","Code":"recras","Description":"Recras verkoopboek"}]}}`)
+				return
+			}
+			fmt.Fprint(w, empty)
+		case "/api/v1/123/cashflow/PaymentConditions":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"pc","Code":"RC","Description":"recras"}]}}`)
+		case "/api/v1/123/vat/VATCodes":
+			if r.URL.Query().Get("$filter") == "" {
+				fmt.Fprint(w, `{"d":{"results":[
+					{"ID":"v2","Code":"2","Description":"BTW 21%","Percentage":0.21,"VATTransactionType":"S","GLToPay":"gl-1500","GLToClaim":"gl-1510"},
+					{"ID":"v3","Code":"3","Description":"BTW 9%","Percentage":0.09,"VATTransactionType":"S","GLToPay":"gl-1501"}
+				]}}`)
+				return
+			}
+			if done {
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"r9","Code":"R9","Description":"recras:9"},{"ID":"r21","Code":"R21","Description":"recras:21"}]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"r9","Code":"R9","Description":"recras:9"}]}}`)
+		case "/api/v1/123/logistics/Units":
+			if done {
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"u","Code":"recras","Description":"Recras"}]}}`)
+				return
+			}
+			fmt.Fprint(w, empty)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	report, err := cl.SetupAdministration(context.Background(), []string{"9", "21", ""})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	expected := []string{"Dagboek recras", "BTW-code R21 (recras:21)", "Eenheid recras"}
+	if !reflect.DeepEqual(report.Created, expected) {
+		t.Errorf("Expected %#v to be created, got %#v", expected, report.Created)
+	}
+	vc := created["/api/v1/123/vat/VATCodes"]
+	if vc["Percentage"] != 0.21 || vc["GLToPay"] != "gl-1500" || vc["Type"] != VATCodeTypeInclusive {
+		t.Errorf("Expected VAT code to copy the GL accounts of the 21%% sales code, got %#v", vc)
+	}
+	if j := created["/api/v1/123/financial/Journals"]; j["Type"] != float64(JournalTypeSales) {
+		t.Errorf("Expected a sales journal, got %#v", j)
+	}
+
+	report, err = cl.SetupAdministration(context.Background(), []string{"9", "21"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(report.Created) != 0 {
+		t.Errorf("Expected a second run to create nothing, got %#v", report.Created)
+	}
+}
+
+func Test_recrasVATCode(t *testing.T) {
+	cases := map[string]string{"21": "R21", "9": "R9", "0": "R0", "5.5": "R55", "10.5": "R10"}
+	for p, code := range cases {
+		if c := recrasVATCode(p); c != code {
+			t.Errorf("Expected code %s for %s, got %s", code, p, c)
+		}
+	}
+}
+
+func TestSetupAdministration_freeCodes(t *testing.T) {
+	created := map[string][]map[string]interface{}{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			created[r.URL.Path] = append(created[r.URL.Path], body)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{"d": body})
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/123/financial/Journals":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"j","Code":"recras"}]}}`)
+		case "/api/v1/123/cashflow/PaymentConditions":
+			if r.URL.Query().Get("$filter") != "" {
+				fmt.Fprint(w, `{"d":{"results":[]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"pc","Code":"RC","Description":"Rembours"}]}}`)
+		case "/api/v1/123/vat/VATCodes":
+			if r.URL.Query().Get("$filter") == "" {
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"r9","Code":"R9","Description":"recras:9"}]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"r9","Code":"R9","Description":"recras:9"}]}}`)
+		case "/api/v1/123/logistics/Units":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"u","Code":"recras"}]}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	report, err := cl.SetupAdministration(context.Background(), []string{"10", "10.5", "9.0"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	expected := []string{"Betalingsconditie recras", "BTW-code R10 (recras:10)", "BTW-code R00 (recras:10.5)"}
+	if !reflect.DeepEqual(report.Created, expected) {
+		t.Errorf("Expected %#v to be created, got %#v", expected, report.Created)
+	}
+	if pc := created["/api/v1/123/cashflow/PaymentConditions"]; len(pc) != 1 || pc[0]["Code"] != "R0" {
+		t.Errorf("Expected the payment condition to get a free code, got %#v", pc)
+	}
+}
+
+func Test_freeCode(t *testing.T) {
+	taken := map[string]bool{"R10": true, "R00": true}
+	if c := freeCode(taken, "R21", "R", 3); c != "R21" {
+		t.Errorf("Expected the preferred code, got %s", c)
+	}
+	if c := freeCode(taken, "r10", "R", 3); c != "R01" {
+		t.Errorf("Expected the first free code, got %s", c)
+	}
+	if c := freeCode(map[string]bool{"R": true}, "R", "R", 1); c != "" {
+		t.Errorf("Expected no code when all are taken, got %s", c)
+	}
+}
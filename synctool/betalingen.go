@@ -0,0 +1,107 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+)
+
+func betalingDescription(b recras.Betaling) string {
+	return fmt.Sprintf("Recras betaling %d", b.ID)
+}
+
+// convertBetaling returns the line booking b as received on receivable r
+func convertBetaling(b recras.Betaling, r exactonline.Receivable) exactonline.FinancialEntryLine {
+	line := exactonline.FinancialEntryLine{
+		Account:     r.Account,
+		AmountFC:    -b.Bedrag,
+		Description: betalingDescription(b),
+		OurRef:      r.InvoiceNumber,
+	}
+	line.Date.Time = b.Datum.Time
+	return line
+}
+
+// syncBetalingen books the payments of f in the bank or cash journal of opts,
+// matched to the receivable of the invoice f was synced to. Payments that were
+// booked before are recognized by their description and skipped.
+func syncBetalingen(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, opts Options, f recras.Factuur) {
+	if opts.BankJournal == "" && opts.CashJournal == "" {
+		return
+	}
+
+	receivables, err := ecl.GetReceivables(ctx, exactonline.Eq("PaymentReference", f.FactuurNummer))
+	if err != nil {
+		logentry.Warnf("Error retrieving receivables: %#v", err)
+		errc <- fmt.Errorf("Betalingen van factuur %s konden niet worden opgehaald uit Exact Online: %s", f.FactuurNummer, httperror.Message(err))
+		return
+	}
+	if len(receivables) == 0 {
+		logentry.Debug("Skipping betalingen: no receivable")
+		return
+	}
+	r := receivables[0]
+	if r.IsFullyPaid {
+		logentry.Debug("Skipping betalingen: receivable is fully paid")
+		return
+	}
+
+	betalingen, err := rcl.GetBetalingen(ctx, f.ID)
+	if err != nil {
+		logentry.Warnf("Error retrieving betalingen: %#v", err)
+		errc <- fmt.Errorf("Betalingen van factuur %s konden niet worden opgehaald uit Recras: %s", f.FactuurNummer, httperror.Message(err))
+		return
+	}
+	for _, b := range betalingen {
+		if math.Abs(b.Bedrag) < 1e-3 {
+			continue
+		}
+		if err := syncBetaling(ctx, logentry.WithField("betaling", b.ID), ecl, opts, b, r); err != nil {
+			errc <- fmt.Errorf("Betaling %d van factuur %s kon niet worden geboekt: %s", b.ID, f.FactuurNummer, httperror.Message(err))
+		}
+	}
+}
+
+func syncBetaling(ctx context.Context, logentry *logrus.Entry, ecl *exactonline.Client, opts Options, b recras.Betaling, r exactonline.Receivable) error {
+	journal := opts.BankJournal
+	find := ecl.FindBankEntryLines
+	if b.IsContant() {
+		journal = opts.CashJournal
+		find = ecl.FindCashEntryLines
+	}
+	if journal == "" {
+		logentry.WithField("betaalmethode", b.Betaalmethode).Debug("Skipping betaling: no journal")
+		return nil
+	}
+
+	existing, err := find(ctx, exactonline.Eq("Description", betalingDescription(b)))
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		logentry.Debug("Betaling exists")
+		return nil
+	}
+
+	line := convertBetaling(b, r)
+	if b.IsContant() {
+		entry := exactonline.CashEntry{JournalCode: journal}
+		entry.SetCashEntryLines([]exactonline.FinancialEntryLine{line})
+		err = entry.Save(ctx, ecl)
+	} else {
+		entry := exactonline.BankEntry{JournalCode: journal}
+		entry.SetBankEntryLines([]exactonline.FinancialEntryLine{line})
+		err = entry.Save(ctx, ecl)
+	}
+	if err != nil {
+		logentry.Warnf("Error saving betaling: %#v", err)
+		return err
+	}
+	logentry.Info("Saved betaling")
+	return nil
+}
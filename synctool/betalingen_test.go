@@ -0,0 +1,93 @@
+package synctool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func Test_convertBetaling(t *testing.T) {
+	b := recras.Betaling{ID: 3, Bedrag: 25, Datum: recras.Date{Time: time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)}}
+	r := exactonline.Receivable{Account: "account-guid", InvoiceNumber: 15000001}
+	line := convertBetaling(b, r)
+	if line.AmountFC != -25 {
+		t.Errorf("Expected a received payment to be negative, got %f", line.AmountFC)
+	}
+	if line.OurRef != 15000001 || line.Account != "account-guid" {
+		t.Errorf("Expected line to be matched to the receivable, got %#v", line)
+	}
+	if line.Description != "Recras betaling 3" {
+		t.Errorf("Expected Description to be %#v, got %#v", "Recras betaling 3", line.Description)
+	}
+}
+
+func Test_syncBetalingen(t *testing.T) {
+	var bankEntries, cashEntries []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api2/facturen/12/betalingen":
+			fmt.Fprint(w, `[
+				{"id": 1, "factuur_id": 12, "bedrag": 10, "betaalmethode": "ideal", "datum": "2015-01-02"},
+				{"id": 2, "factuur_id": 12, "bedrag": 5, "betaalmethode": "contant", "datum": "2015-01-03"},
+				{"id": 3, "factuur_id": 12, "bedrag": 7, "betaalmethode": "pin", "datum": "2015-01-04"}
+			]`)
+		case "/api/v1/123/cashflow/Receivables":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"r1","Account":"account-guid","AmountDC":22,"DueDate":"/Date(1405036800000)/","InvoiceNumber":15000001,"PaymentReference":"1-2-3"}]}}`)
+		case "/api/v1/123/financialtransaction/BankEntryLines":
+			if r.URL.Query().Get("$filter") == "Description eq 'Recras betaling 3'" {
+				fmt.Fprint(w, `{"d":{"results":[{"ID":"line-guid","AmountFC":-7,"Date":"/Date(1405036800000)/","Description":"Recras betaling 3"}]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		case "/api/v1/123/financialtransaction/CashEntryLines":
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		case "/api/v1/123/financialtransaction/BankEntries", "/api/v1/123/financialtransaction/CashEntries":
+			var e map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&e)
+			if r.URL.Path == "/api/v1/123/financialtransaction/BankEntries" {
+				bankEntries = append(bankEntries, e)
+			} else {
+				cashEntries = append(cashEntries, e)
+			}
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"EntryID":"entry-guid"}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl = cl.WithDivision(123)
+
+	rcl := recras.NewClient("test.recras.nl", "username", "password")
+	base, _ := url.Parse(ts.URL)
+	rcl.Client.Transport = &recras.Transport{BaseURL: base}
+
+	errc := make(chan error, 10)
+	f := recras.Factuur{ID: 12, FactuurNummer: "1-2-3", Status: recras.StatusBetaald}
+	opts := Options{BankJournal: "20", CashJournal: "10"}
+	syncBetalingen(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, &rcl, cl, opts, f)
+	close(errc)
+	for err := range errc {
+		t.Errorf("Expected no errors, got %#v", err)
+	}
+
+	if len(bankEntries) != 1 || bankEntries[0]["JournalCode"] != "20" {
+		t.Errorf("Expected only the new iDEAL payment to be booked in the bank journal, got %#v", bankEntries)
+	}
+	if len(cashEntries) != 1 || cashEntries[0]["JournalCode"] != "10" {
+		t.Errorf("Expected the cash payment to be booked in the cash journal, got %#v", cashEntries)
+	}
+}
@@ -0,0 +1,100 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+)
+
+// CostRule books the revenue of the factuurregels it matches on CostCenter
+// and CostUnit. It matches a regel when each of ProductID, ProductgroepID
+// and LocatieID is zero or equal to that of the regel.
+type CostRule struct {
+	ProductID      int
+	ProductgroepID int
+	LocatieID      int
+
+	CostCenter string
+	CostUnit   string
+}
+
+// validCostRules returns the rules of which the cost center and cost unit
+// exist in the division of ecl. Codes exist per division, so the other rules
+// are reported to errc once and skipped, instead of failing every invoice
+// they match.
+func validCostRules(ctx context.Context, logentry *logrus.Entry, errc chan<- error, ecl *exactonline.Client, rules []CostRule) []CostRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	centers, err := ecl.GetCostCenters(ctx)
+	if err != nil {
+		logentry.Warnf("Error retrieving cost centers: %#v", err)
+		errc <- fmt.Errorf("Kostenplaatsen konden niet worden opgehaald, facturen worden zonder kostenplaats geboekt: %s", err)
+		return nil
+	}
+	units, err := ecl.GetCostUnits(ctx)
+	if err != nil {
+		logentry.Warnf("Error retrieving cost units: %#v", err)
+		errc <- fmt.Errorf("Kostendragers konden niet worden opgehaald, facturen worden zonder kostendrager geboekt: %s", err)
+		return nil
+	}
+	knownCenters := map[string]bool{}
+	for _, c := range centers {
+		if c.Active {
+			knownCenters[c.Code] = true
+		}
+	}
+	knownUnits := map[string]bool{}
+	for _, u := range units {
+		knownUnits[u.Code] = true
+	}
+
+	out := make([]CostRule, 0, len(rules))
+	for _, r := range rules {
+		if r.CostCenter != "" && !knownCenters[r.CostCenter] {
+			errc <- fmt.Errorf("Regel voor kostenplaats `%s` wordt overgeslagen: de kostenplaats bestaat niet in administratie %d", r.CostCenter, ecl.Division())
+			continue
+		}
+		if r.CostUnit != "" && !knownUnits[r.CostUnit] {
+			errc <- fmt.Errorf("Regel voor kostendrager `%s` wordt overgeslagen: de kostendrager bestaat niet in administratie %d", r.CostUnit, ecl.Division())
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// costAssigner picks the cost center and cost unit of factuurregels with the
+// first matching rule
+type costAssigner struct {
+	rules []CostRule
+	// productgroepen holds productgroep IDs by product ID
+	productgroepen map[int]int
+}
+
+func newCostAssigner(rules []CostRule, producten []recras.Product) costAssigner {
+	ca := costAssigner{rules: rules, productgroepen: make(map[int]int, len(producten))}
+	for _, p := range producten {
+		ca.productgroepen[p.ID] = p.ProductgroepID
+	}
+	return ca
+}
+
+func (ca costAssigner) assign(regel recras.Factuurregel) (costCenter, costUnit string) {
+	for _, r := range ca.rules {
+		if r.ProductID != 0 && r.ProductID != regel.ProductID {
+			continue
+		}
+		if r.ProductgroepID != 0 && r.ProductgroepID != ca.productgroepen[regel.ProductID] {
+			continue
+		}
+		if r.LocatieID != 0 && r.LocatieID != regel.LocatieID {
+			continue
+		}
+		return r.CostCenter, r.CostUnit
+	}
+	return "", ""
+}
@@ -0,0 +1,103 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func Test_costAssigner(t *testing.T) {
+	ca := newCostAssigner([]CostRule{
+		{ProductID: 1, CostCenter: "100", CostUnit: "KANO"},
+		{ProductgroepID: 3, LocatieID: 7, CostCenter: "200"},
+		{CostCenter: "900"},
+	}, []recras.Product{{ID: 1, ProductgroepID: 3}, {ID: 2, ProductgroepID: 3}})
+
+	for _, tc := range []struct {
+		regel      recras.Factuurregel
+		costCenter string
+		costUnit   string
+	}{
+		{recras.Factuurregel{ProductID: 1, LocatieID: 7}, "100", "KANO"},
+		{recras.Factuurregel{ProductID: 2, LocatieID: 7}, "200", ""},
+		{recras.Factuurregel{ProductID: 2, LocatieID: 8}, "900", ""},
+	} {
+		center, unit := ca.assign(tc.regel)
+		if center != tc.costCenter || unit != tc.costUnit {
+			t.Errorf("Expected %s/%s for %#v, got %s/%s", tc.costCenter, tc.costUnit, tc.regel, center, unit)
+		}
+	}
+
+	if center, unit := (costAssigner{}).assign(recras.Factuurregel{ProductID: 1}); center != "" || unit != "" {
+		t.Errorf("Expected no cost center without rules, got %s/%s", center, unit)
+	}
+}
+
+func Test_convertFactuurregels_costCenter(t *testing.T) {
+	ca := newCostAssigner([]CostRule{{ProductID: 1, CostCenter: "100", CostUnit: "KANO"}}, nil)
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
+		Type:          recras.FactuurregelItem,
+		Aantal:        1,
+		Bedrag:        10,
+		BTWPercentage: 21,
+		ProductID:     1,
+	}}, 1, exactonline.VATCodeList{21: "R21"}, ca)
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(lines) != 1 || lines[0].CostCenter != "100" || lines[0].CostUnit != "KANO" {
+		t.Errorf("Expected the line to get the cost center of its product, got %#v", lines)
+	}
+}
+
+func Test_validCostRules(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/hrm/Costcenters":
+			fmt.Fprint(w, `{"d":{"results":[{"Code":"100","Active":true},{"Code":"300","Active":false}]}}`)
+		case "/api/v1/123/hrm/Costunits":
+			fmt.Fprint(w, `{"d":{"results":[{"Code":"KANO"}]}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)}).WithDivision(123)
+
+	errc := make(chan error, 10)
+	rules := []CostRule{
+		{ProductID: 1, CostCenter: "100", CostUnit: "KANO"},
+		{ProductID: 2, CostCenter: "200"},
+		{ProductID: 3, CostCenter: "300"},
+		{ProductID: 4, CostUnit: "FIETS"},
+		{ProductID: 5, CostUnit: "KANO"},
+	}
+	valid := validCostRules(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, cl, rules)
+	close(errc)
+	expected := []CostRule{rules[0], rules[4]}
+	if !reflect.DeepEqual(valid, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, valid)
+	}
+	n := 0
+	for range errc {
+		n++
+	}
+	if n != 3 {
+		t.Errorf("Expected each unknown code to be reported once, got %d errors", n)
+	}
+
+	if valid := validCostRules(context.Background(), nil, nil, nil, nil); valid != nil {
+		t.Errorf("Expected no rules without rules, got %#v", valid)
+	}
+}
@@ -0,0 +1,63 @@
+package synctool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+)
+
+// ClosedPeriodPolicy decides what happens to invoices dated in a closed or
+// missing financial period, which Exact Online refuses to book
+type ClosedPeriodPolicy string
+
+const (
+	// SkipClosedPeriods leaves the invoice out and reports why
+	SkipClosedPeriods ClosedPeriodPolicy = "skip"
+	// BookOnFirstOpenDate books the invoice on the first open date after its
+	// own date, which is kept in the description
+	BookOnFirstOpenDate ClosedPeriodPolicy = "first_open"
+)
+
+// bookingPeriods holds the financial periods of a division. Without periods
+// every date is taken to be open.
+type bookingPeriods struct {
+	periods exactonline.FinancialPeriodList
+	policy  ClosedPeriodPolicy
+}
+
+// bookingDate returns the date f is booked on following the policy, or the
+// reason it can not be booked
+func (bp bookingPeriods) bookingDate(f recras.Factuur) (time.Time, error) {
+	date := f.Datum.Time
+	if bp.periods == nil || date.IsZero() {
+		return date, nil
+	}
+	p, err := bp.periods.Find(date)
+	if err == nil && p.IsOpen() {
+		return date, nil
+	}
+
+	reason := fmt.Sprintf("factuurdatum %s valt in een afgesloten periode", date.Format("02-01-2006"))
+	if err != nil {
+		reason = fmt.Sprintf("boekjaar van factuurdatum %s bestaat niet in Exact Online", date.Format("02-01-2006"))
+	}
+	if bp.policy != BookOnFirstOpenDate {
+		return time.Time{}, fmt.Errorf("Factuur %s wordt overgeslagen: %s", f.FactuurNummer, reason)
+	}
+	open, err := bp.periods.FirstOpenDate(date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Factuur %s wordt overgeslagen: %s en er is geen latere open periode", f.FactuurNummer, reason)
+	}
+	return open, nil
+}
+
+// movedDescription returns description noting the original date of f when
+// it is booked on another date
+func movedDescription(description string, f recras.Factuur, date time.Time) string {
+	if date.Equal(f.Datum.Time) {
+		return description
+	}
+	return fmt.Sprintf("%s (factuurdatum %s)", description, f.Datum.Time.Format("02-01-2006"))
+}
@@ -0,0 +1,47 @@
+package synctool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/odata2json"
+	"github.com/Recras/exactonline/recras"
+)
+
+func Test_bookingDate(t *testing.T) {
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2015, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	periods := exactonline.FinancialPeriodList{
+		{StartDate: odata2json.Date{Time: day(1, 1)}, EndDate: odata2json.Date{Time: day(1, 31)}, Closed: true},
+		{StartDate: odata2json.Date{Time: day(2, 1)}, EndDate: odata2json.Date{Time: day(2, 28)}},
+	}
+	closed := recras.Factuur{FactuurNummer: "1-2-3", Datum: recras.Date{Time: day(1, 15)}}
+	open := recras.Factuur{FactuurNummer: "1-2-4", Datum: recras.Date{Time: day(2, 15)}}
+	missing := recras.Factuur{FactuurNummer: "1-2-5", Datum: recras.Date{Time: day(3, 15)}}
+
+	skip := bookingPeriods{periods, SkipClosedPeriods}
+	if d, err := skip.bookingDate(open); err != nil || !d.Equal(day(2, 15)) {
+		t.Errorf("Expected an open date to be kept, got %s, %#v", d, err)
+	}
+	if _, err := skip.bookingDate(closed); err == nil {
+		t.Errorf("Expected an invoice in a closed period to be skipped")
+	}
+
+	move := bookingPeriods{periods, BookOnFirstOpenDate}
+	d, err := move.bookingDate(closed)
+	if err != nil || !d.Equal(day(2, 1)) {
+		t.Errorf("Expected the first open date, got %s, %#v", d, err)
+	}
+	if desc := movedDescription("Recras factuur: 1-2-3", closed, d); desc != "Recras factuur: 1-2-3 (factuurdatum 15-01-2015)" {
+		t.Errorf("Expected the original date in the description, got %#v", desc)
+	}
+	if _, err := move.bookingDate(missing); err == nil {
+		t.Errorf("Expected an invoice without a later open period to be skipped")
+	}
+
+	if d, err := (bookingPeriods{}).bookingDate(closed); err != nil || !d.Equal(day(1, 15)) {
+		t.Errorf("Expected dates not to be checked without periods, got %s, %#v", d, err)
+	}
+}
@@ -0,0 +1,319 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/odata2json"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+)
+
+// defaultUnit is the unit SetupAdministration creates, used for producten
+// without an eenheid
+const defaultUnit = "recras"
+
+// itemCatalog holds the units, item groups and prices of a division, so
+// they are retrieved once per sync instead of once per product. What could
+// not be retrieved is left alone, so items are then synced as plain items.
+type itemCatalog struct {
+	ecl      *exactonline.Client
+	vatcodes exactonline.VATCodeList
+
+	units []exactonline.Unit
+	// groups holds ItemGroup IDs by Recras productgroep ID
+	groups         map[int]string
+	defaultGroup   string
+	productgroepen map[int]string
+	// prices holds the general SalesItemPrice by Item ID
+	prices map[string]exactonline.SalesItemPrice
+	// vatTypes holds the VAT codes of the division by Code
+	vatTypes map[string]exactonline.VATCode
+
+	skipUnits, skipGroups, skipPrices bool
+}
+
+func newItemCatalog(ctx context.Context, logentry *logrus.Entry, rcl *recras.Client, ecl *exactonline.Client, vatcodes exactonline.VATCodeList) *itemCatalog {
+	cat := &itemCatalog{
+		ecl:            ecl,
+		vatcodes:       vatcodes,
+		groups:         map[int]string{},
+		productgroepen: map[int]string{},
+		prices:         map[string]exactonline.SalesItemPrice{},
+		vatTypes:       map[string]exactonline.VATCode{},
+	}
+
+	var err error
+	if cat.units, err = ecl.GetUnits(ctx); err != nil {
+		logentry.Warnf("Error retrieving units, using the default unit: %#v", err)
+		cat.skipUnits = true
+	}
+
+	if err := cat.loadGroups(ctx); err != nil {
+		logentry.Warnf("Error retrieving item groups, leaving them alone: %#v", err)
+		cat.skipGroups = true
+	}
+	pgs, err := rcl.GetProductgroepen(ctx)
+	if err != nil {
+		logentry.Warnf("Error retrieving productgroepen: %#v", err)
+	}
+	for _, pg := range pgs {
+		cat.productgroepen[pg.ID] = pg.Naam
+	}
+
+	if err := cat.loadPrices(ctx); err != nil {
+		logentry.Warnf("Error retrieving prices or VAT codes, leaving prices alone: %#v", err)
+		cat.skipPrices = true
+	}
+	return cat
+}
+
+func (cat *itemCatalog) loadGroups(ctx context.Context) error {
+	groups, err := cat.ecl.GetItemGroups(ctx)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		var id int
+		if n, _ := fmt.Sscanf(g.Code, "recras%d", &id); n == 1 {
+			cat.groups[id] = g.ID
+		}
+	}
+	if g, err := cat.ecl.FindDefaultItemGroup(ctx); err == nil {
+		cat.defaultGroup = g.ID
+	} else if err != exactonline.ErrNoDefaultItemGroup {
+		return err
+	}
+	return nil
+}
+
+func (cat *itemCatalog) loadPrices(ctx context.Context) error {
+	prices, err := cat.ecl.GetSalesItemPrices(ctx, exactonline.StartsWith("ItemCode", "recras"))
+	if err != nil {
+		return err
+	}
+	for _, p := range prices {
+		if _, ok := cat.prices[p.Item]; !ok && p.Account == "" {
+			cat.prices[p.Item] = p
+		}
+	}
+	vcs, err := cat.ecl.GetVATCodes(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, vc := range vcs {
+		cat.vatTypes[vc.Code] = vc
+	}
+	return nil
+}
+
+// unitCode returns the preferred unit code for eenheid, of at most the 8
+// characters Exact Online allows
+func unitCode(eenheid string) string {
+	code := strings.ToLower(strings.Replace(eenheid, " ", "", -1))
+	if len(code) > 8 {
+		code = code[:8]
+	}
+	return code
+}
+
+// freeUnitCode returns code when no unit has it yet, or else code with a
+// number that no unit has, so eenheden sharing a prefix get their own unit
+func (cat *itemCatalog) freeUnitCode(code string) (string, error) {
+	taken := map[string]bool{}
+	for _, u := range cat.units {
+		taken[strings.ToLower(u.Code)] = true
+	}
+	if !taken[code] {
+		return code, nil
+	}
+	if len(code) > 6 {
+		code = code[:6]
+	}
+	for i := 1; i < 100; i++ {
+		if c := fmt.Sprintf("%s%02d", code, i); !taken[c] {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("no free unit code left for %s", code)
+}
+
+// unit returns the code of the unit for eenheid, creating it when it is
+// missing. Units are matched on their description, or on their code when
+// that is eenheid in full.
+func (cat *itemCatalog) unit(ctx context.Context, eenheid string) (string, error) {
+	eenheid = strings.TrimSpace(eenheid)
+	if eenheid == "" || cat.skipUnits {
+		return defaultUnit, nil
+	}
+	for _, u := range cat.units {
+		if strings.EqualFold(u.Description, eenheid) {
+			return u.Code, nil
+		}
+	}
+	code := unitCode(eenheid)
+	if code == strings.ToLower(strings.Replace(eenheid, " ", "", -1)) {
+		for _, u := range cat.units {
+			if strings.EqualFold(u.Code, code) {
+				return u.Code, nil
+			}
+		}
+	}
+	code, err := cat.freeUnitCode(code)
+	if err != nil {
+		return "", err
+	}
+	u := exactonline.Unit{Code: code, Description: eenheid, Type: exactonline.UnitTypeOther, Active: true}
+	if err := u.Save(ctx, cat.ecl); err != nil {
+		return "", err
+	}
+	cat.units = append(cat.units, u)
+	return u.Code, nil
+}
+
+// itemGroup returns the ID of the item group for productgroep id, creating
+// it when it is missing. Producten without a productgroep get the default
+// item group.
+func (cat *itemCatalog) itemGroup(ctx context.Context, id int) (string, error) {
+	if cat.skipGroups {
+		return "", nil
+	}
+	if g, ok := cat.groups[id]; ok {
+		return g, nil
+	}
+	naam, ok := cat.productgroepen[id]
+	if id == 0 || !ok {
+		return cat.defaultGroup, nil
+	}
+	g := exactonline.ItemGroup{Code: fmt.Sprintf("recras%d", id), Description: naam}
+	if err := g.Save(ctx, cat.ecl); err != nil {
+		return "", err
+	}
+	cat.groups[id] = g.ID
+	return g.ID, nil
+}
+
+// salesPrice returns the price of p for item. Recras prices include VAT,
+// Exact Online only takes the price of an item with an inclusive VAT code as
+// such and the price without VAT otherwise.
+func (cat *itemCatalog) salesPrice(item exactonline.Item, p recras.Product) float64 {
+	vc, ok := cat.vatTypes[item.SalesVatCode]
+	if !ok {
+		return p.Verkoop / (1 + p.BTWPercentage/100)
+	}
+	if vc.Type == exactonline.VATCodeTypeInclusive {
+		return p.Verkoop
+	}
+	return p.Verkoop / (1 + vc.Percentage)
+}
+
+// syncPrice makes the general price of item the price of p
+func (cat *itemCatalog) syncPrice(ctx context.Context, item exactonline.Item, p recras.Product) error {
+	if cat.skipPrices {
+		return nil
+	}
+	verkoop := cat.salesPrice(item, p)
+	price, ok := cat.prices[item.ID]
+	if !ok {
+		if verkoop == 0 {
+			return nil
+		}
+		price = exactonline.SalesItemPrice{Item: item.ID, Price: verkoop, Unit: item.Unit}
+		if err := price.Save(ctx, cat.ecl); err != nil {
+			return err
+		}
+		cat.prices[item.ID] = price
+		return nil
+	}
+	if math.Abs(price.Price-verkoop) < 0.005 {
+		return nil
+	}
+	orig := price
+	price.Price = verkoop
+	if err := price.Update(ctx, cat.ecl, orig); err != nil {
+		return err
+	}
+	cat.prices[item.ID] = price
+	return nil
+}
+
+// syncProducten syncs the Recras producten to items and returns them
+func syncProducten(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, vatcodes exactonline.VATCodeList) ([]recras.Product, error) {
+	producten, err := rcl.GetAllProducten(ctx)
+	if err != nil {
+		logentry.Warnf("Error retrieving producten from Recras")
+		return nil, err
+	}
+	cat := newItemCatalog(ctx, logentry, rcl, ecl, vatcodes)
+	for _, p := range producten {
+		_, err := syncProduct(ctx, logentry.WithField("RecrasProduct", p.ID), p, cat)
+		if err != nil {
+			logentry.WithFields(logrus.Fields{
+				"RecrasProduct": p.ID,
+				"error":         err,
+			}).Warn("Error syncing Product")
+			errc <- err
+		}
+	}
+	return producten, nil
+}
+
+// syncProduct creates the Item for p with its unit, item group and VAT code,
+// or brings the item group and VAT code of an existing one up to date. The
+// unit of an existing item is left alone, as its prices and transactions
+// use it. The price is kept equal to the Recras price either way.
+func syncProduct(ctx context.Context, logentry *logrus.Entry, p recras.Product, cat *itemCatalog) (exactonline.Item, error) {
+	ecl := cat.ecl
+	group, err := cat.itemGroup(ctx, p.ProductgroepID)
+	if err != nil {
+		return exactonline.Item{}, err
+	}
+	vatcode := cat.vatcodes[p.BTWPercentage]
+
+	item, err := ecl.FindItemByRecrasID(ctx, p.ID)
+	if err == nil {
+		logentry.Info("Found item")
+		orig := item
+		if group != "" {
+			item.ItemGroup = group
+		}
+		if vatcode != "" {
+			item.SalesVatCode = vatcode
+		}
+		// Update only sends what changed, if anything
+		if err := item.Update(ctx, ecl, orig); err != nil {
+			return exactonline.Item{}, err
+		}
+	} else if _, ok := err.(exactonline.ErrItemNotFound); !ok {
+		return exactonline.Item{}, err
+	} else {
+		unit, err := cat.unit(ctx, p.Eenheid)
+		if err != nil {
+			return exactonline.Item{}, err
+		}
+		item.Code = fmt.Sprintf("recras%d", p.ID)
+		item.Description = fmt.Sprintf("Recras p%d: %s", p.ID, p.Naam)
+		item.StartDate = odata2json.Date{Time: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)}
+		item.IsSalesItem = true
+		item.Unit = unit
+		item.ItemGroup = group
+		item.SalesVatCode = vatcode
+		if err := item.Save(ctx, ecl); err != nil {
+			if e, ok := err.(httperror.HTTPError); ok {
+				logentry.Debug("response" + string(e.Body))
+				logentry.Debug("request" + string(e.RequestBody))
+			}
+			return exactonline.Item{}, err
+		}
+	}
+
+	if err := cat.syncPrice(ctx, item, p); err != nil {
+		return exactonline.Item{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,240 @@
+package synctool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func Test_unitCode(t *testing.T) {
+	for in, expected := range map[string]string{
+		"Stuk":            "stuk",
+		"per persoon":     "perperso",
+		"Dagdeel (4 uur)": "dagdeel(",
+		"uur":             "uur",
+	} {
+		if code := unitCode(in); code != expected {
+			t.Errorf("Expected %#v for %#v, got %#v", expected, in, code)
+		}
+	}
+}
+
+func Test_syncProducten(t *testing.T) {
+	var saved = map[string][]map[string]interface{}{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var e map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&e)
+			saved[r.URL.Path] = append(saved[r.URL.Path], e)
+			e["ID"] = fmt.Sprintf("guid%d", len(saved[r.URL.Path]))
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{"d": e})
+			return
+		}
+		switch r.URL.Path {
+		case "/api2/producten":
+			fmt.Fprint(w, `[
+				{"id": 1, "naam": "Kanoën", "verkoop": 12.5, "btw_percentage": 21, "eenheid": "persoon", "productgroep_id": 3},
+				{"id": 2, "naam": "Lunch", "verkoop": 9, "btw_percentage": 9, "eenheid": "", "productgroep_id": 0}
+			]`)
+		case "/api2/productgroepen":
+			fmt.Fprint(w, `[{"id": 3, "naam": "Activiteiten"}]`)
+		case "/api/v1/123/logistics/Units":
+			fmt.Fprint(w, `{"d":{"results":[{"Code":"recras","Description":"Recras"}]}}`)
+		case "/api/v1/123/logistics/ItemGroups":
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"default-guid","Code":"1"}]}}`)
+		case "/api/v1/123/logistics/SalesItemPrices":
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		case "/api/v1/123/vat/VATCodes":
+			fmt.Fprint(w, `{"d":{"results":[{"Code":"R21","Percentage":0.21,"Type":"I"},{"Code":"R9","Percentage":0.09,"Type":"E"}]}}`)
+		case "/api/v1/123/logistics/Items":
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl = cl.WithDivision(123)
+
+	rcl := recras.NewClient("test.recras.nl", "username", "password")
+	base, _ := url.Parse(ts.URL)
+	rcl.Client.Transport = &recras.Transport{BaseURL: base}
+
+	errc := make(chan error, 10)
+	vatcodes := exactonline.VATCodeList{21: "R21", 9: "R9"}
+	if _, err := syncProducten(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, &rcl, cl, vatcodes); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	close(errc)
+	for err := range errc {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	units := saved["/api/v1/123/logistics/Units"]
+	if len(units) != 1 || units[0]["Code"] != "persoon" {
+		t.Errorf("Expected unit persoon to be created, got %#v", units)
+	}
+	groups := saved["/api/v1/123/logistics/ItemGroups"]
+	if len(groups) != 1 || groups[0]["Code"] != "recras3" || groups[0]["Description"] != "Activiteiten" {
+		t.Errorf("Expected item group recras3 to be created, got %#v", groups)
+	}
+
+	items := saved["/api/v1/123/logistics/Items"]
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %#v", items)
+	}
+	if items[0]["Unit"] != "persoon" || items[0]["ItemGroup"] != "guid1" || items[0]["SalesVatCode"] != "R21" {
+		t.Errorf("Expected the unit, group and VAT code of product 1, got %#v", items[0])
+	}
+	if items[1]["Unit"] != "recras" || items[1]["ItemGroup"] != "default-guid" || items[1]["SalesVatCode"] != "R9" {
+		t.Errorf("Expected the default unit and group for product 2, got %#v", items[1])
+	}
+
+	prices := saved["/api/v1/123/logistics/SalesItemPrices"]
+	if len(prices) != 2 || prices[0]["Price"] != 12.5 || prices[0]["Item"] != "guid1" {
+		t.Fatalf("Expected the Recras price for the inclusive VAT code, got %#v", prices)
+	}
+	if p, _ := prices[1]["Price"].(float64); math.Abs(p-9/1.09) > 1e-9 {
+		t.Errorf("Expected the price without VAT for the exclusive VAT code, got %#v", prices[1])
+	}
+}
+
+func Test_syncProducten_plain(t *testing.T) {
+	var saved = map[string][]map[string]interface{}{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var e map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&e)
+			saved[r.URL.Path] = append(saved[r.URL.Path], e)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{"d": e})
+			return
+		}
+		switch r.URL.Path {
+		case "/api2/producten":
+			fmt.Fprint(w, `[{"id": 1, "naam": "Kanoën", "verkoop": 12.5, "btw_percentage": 21, "eenheid": "persoon", "productgroep_id": 3}]`)
+		case "/api2/productgroepen":
+			fmt.Fprint(w, `[{"id": 3, "naam": "Activiteiten"}]`)
+		case "/api/v1/123/logistics/Items":
+			fmt.Fprint(w, `{"d":{"results":[]}}`)
+		default:
+			w.WriteHeader(400)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)}).WithDivision(123)
+
+	rcl := recras.NewClient("test.recras.nl", "username", "password")
+	base, _ := url.Parse(ts.URL)
+	rcl.Client.Transport = &recras.Transport{BaseURL: base}
+
+	errc := make(chan error, 10)
+	if _, err := syncProducten(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, &rcl, cl, exactonline.VATCodeList{21: "R21"}); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	close(errc)
+	for err := range errc {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	items := saved["/api/v1/123/logistics/Items"]
+	if len(items) != 1 || items[0]["Unit"] != "recras" || items[0]["ItemGroup"] != nil {
+		t.Errorf("Expected a plain item when units and item groups cannot be retrieved, got %#v", items)
+	}
+	if len(saved) != 1 {
+		t.Errorf("Expected only the item to be created, got %#v", saved)
+	}
+}
+
+func Test_syncProduct_existing(t *testing.T) {
+	var saved []map[string]interface{}
+	var updated map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			var e map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&e)
+			saved = append(saved, e)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{"d": e})
+		case "PUT":
+			if r.URL.Path != "/api/v1/123/logistics/Items(guid'item-guid')" {
+				t.Errorf("Unexpected path %#v", r.URL.Path)
+			}
+			json.NewDecoder(r.Body).Decode(&updated)
+			w.WriteHeader(204)
+		default:
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"item-guid","Code":"recras1","Unit":"perperso","ItemGroup":"group-guid","SalesVatCode":"R9"}]}}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cat := &itemCatalog{
+		ecl:      cl.WithDivision(123),
+		vatcodes: exactonline.VATCodeList{21: "R21", 9: "R9"},
+		units:    []exactonline.Unit{{Code: "perperso", Description: "per persoon"}},
+		groups:   map[int]string{3: "group-guid"},
+		prices:   map[string]exactonline.SalesItemPrice{"item-guid": {Price: 10}},
+		vatTypes: map[string]exactonline.VATCode{"R21": {Code: "R21", Percentage: 0.21, Type: exactonline.VATCodeTypeInclusive}},
+	}
+
+	p := recras.Product{ID: 1, Verkoop: 10, BTWPercentage: 21, Eenheid: "per personeelslid", ProductgroepID: 3}
+	if _, err := syncProduct(context.Background(), logrus.NewEntry(logrus.StandardLogger()), p, cat); err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(saved) != 0 {
+		t.Errorf("Expected no unit to be created for an existing item, got %#v", saved)
+	}
+	expected := map[string]interface{}{"SalesVatCode": "R21"}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Errorf("Expected %#v to be updated, got %#v", expected, updated)
+	}
+}
+
+func Test_itemCatalog_unit(t *testing.T) {
+	var saved []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&e)
+		saved = append(saved, e)
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(map[string]interface{}{"d": e})
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cat := &itemCatalog{
+		ecl:   c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)}).WithDivision(123),
+		units: []exactonline.Unit{{Code: "perperso", Description: "per persoon"}},
+	}
+
+	if code, err := cat.unit(context.Background(), "Per Persoon"); err != nil || code != "perperso" {
+		t.Errorf("Expected the unit to be matched on its description, got %#v and %#v", code, err)
+	}
+	code, err := cat.unit(context.Background(), "per personeelslid")
+	if err != nil || code != "perper01" {
+		t.Errorf("Expected a unit with a code of its own, got %#v and %#v", code, err)
+	}
+	if len(saved) != 1 || saved[0]["Description"] != "per personeelslid" {
+		t.Errorf("Expected one unit to be created, got %#v", saved)
+	}
+}
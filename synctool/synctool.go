@@ -2,12 +2,14 @@ package synctool
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"math"
+	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,23 +24,100 @@ import (
 	"github.com/Sirupsen/logrus"
 )
 
-func Sync(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, syncdate string) {
+// BookingMode decides how Recras invoices end up in Exact Online
+type BookingMode string
+
+const (
+	// BookSalesEntries books every invoice directly in the ledger
+	BookSalesEntries BookingMode = "salesentry"
+	// BookSalesInvoices creates and processes an invoice in the invoicing
+	// module of Exact Online, so reminders and reporting work on it
+	BookSalesInvoices BookingMode = "salesinvoice"
+)
+
+// Options configures a Sync
+type Options struct {
+	// StartDate (yyyy-mm-dd) is the date of the oldest invoice to sync
+	StartDate   string
+	BookingMode BookingMode
+
+	// BankJournal and CashJournal are the journal codes payments are booked
+	// in. Payments are only synced for the journals that are set.
+	BankJournal string
+	CashJournal string
+
+	// Parallel is the number of bedrijven synced at the same time, at least
+	// one. Every bedrijf has its own division and thus its own rate limit.
+	Parallel int
+
+	// Divisions maps Recras bedrijf IDs to the division they are synced to.
+	// Other bedrijven are synced to the division with their BTWNummer.
+	Divisions map[int]int
+
+	ClosedPeriodPolicy ClosedPeriodPolicy
+
+	// CostRules pick the cost center and cost unit of invoice lines, the
+	// first matching rule wins
+	CostRules []CostRule
+}
+
+func Sync(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, opts Options) {
 	logrus.Debug("get Recras bedrijven")
-	bedrijven, err := rcl.GetBedrijven(nil)
+	bedrijven, err := rcl.GetBedrijven(ctx, nil)
 	if err != nil {
 		errc <- err
 	}
+	divisions, err := ecl.ListDivisions(ctx)
+	if err != nil {
+		logentry.Warnf("Error retrieving divisions: %#v", err)
+		errc <- err
+		return
+	}
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	defer wg.Wait()
 	for _, b := range bedrijven {
-		logentry := logrus.WithField("recras_bedrijf", b)
-		err := syncBedrijf(logentry, errc, rcl, ecl, syncdate, b)
-		if err != nil {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
 			errc <- err
+			return
 		}
+		wg.Add(1)
+		go func(b recras.Bedrijf) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			logentry := logrus.WithField("recras_bedrijf", b)
+			err := syncBedrijf(ctx, logentry, errc, rcl, ecl, opts, divisions, b)
+			if err != nil {
+				errc <- err
+			}
+		}(b)
+	}
+}
+
+// bedrijfDivision returns a handle for the division b is synced to: the one
+// in opts.Divisions, or else the one of divisions with the BTWNummer of b
+func bedrijfDivision(ecl *exactonline.Client, opts Options, divisions []exactonline.Division, b recras.Bedrijf) (*exactonline.Client, error) {
+	if division, ok := opts.Divisions[b.ID]; ok {
+		return ecl.WithDivision(division), nil
+	}
+	div, err := exactonline.MatchDivisionByVATNumber(divisions, b.BTWNummer)
+	if err != nil {
+		return nil, err
 	}
+	return ecl.WithDivision(div.Code), nil
 }
 
-func syncBedrijf(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, syncdate string, b recras.Bedrijf) error {
-	err := ecl.SetDivisionByVATNumber(b.BTWNummer)
+// syncBedrijf syncs b to its division, see Options.Divisions. ecl is not
+// changed, so bedrijven can be synced in parallel.
+func syncBedrijf(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, opts Options, divisions []exactonline.Division, b recras.Bedrijf) error {
+	ecl, err := bedrijfDivision(ecl, opts, divisions, b)
 	if err == exactonline.ErrDivisionNotFound {
 		logentry.Debug("Skipping: no matching BTWNummer")
 		errc <- errors.New(fmt.Sprintf("Bedrijf %s wordt overgeslagen: geen matchend BTW-nummer `%s` in Exact Online", b.Bedrijfsnaam, b.BTWNummer))
@@ -52,13 +131,13 @@ func syncBedrijf(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		return err
 	}
 
-	pc, err := ecl.FindPaymentConditionByDescription("recras")
+	pc, err := ecl.FindPaymentConditionByDescription(ctx, "recras")
 	if err != nil {
 		logentry.Warnf("Error finding PaymentCondition `recras`")
 		return err
 	}
 
-	vcs, err := ecl.GetRecrasVATCodes()
+	vcs, err := ecl.GetRecrasVATCodes(ctx)
 	if err != nil {
 		logentry.Warnf("Error retrieving VATCodes: %#v", err)
 		return err
@@ -70,26 +149,54 @@ func syncBedrijf(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		vatcodes[i] = vc.Code
 	}
 
-	if err := syncProducten(logentry, errc, rcl, ecl); err != nil {
+	producten, err := syncProducten(ctx, logentry, errc, rcl, ecl, vatcodes)
+	if err != nil {
 		logentry.Warnf("Error syncing producten")
 		return err
 	}
+	costs := newCostAssigner(validCostRules(ctx, logentry, errc, ecl, opts.CostRules), producten)
+
+	dc := divisionValuta(divisions, ecl.Division())
+	if dc == "" {
+		logentry.Warn("Unknown division currency, amounts in it are not checked")
+	}
+
+	bp := bookingPeriods{policy: opts.ClosedPeriodPolicy}
+	if bp.periods, err = ecl.GetFinancialPeriods(ctx, ""); err != nil {
+		logentry.Warnf("Error retrieving financial periods, dates are not checked: %#v", err)
+	} else if statuses, err := ecl.GetJournalStatuses(ctx, "recras"); err != nil {
+		logentry.Warnf("Error retrieving journal statuses, periods are taken to be open: %#v", err)
+	} else {
+		bp.periods = bp.periods.CloseJournal(statuses)
+	}
 
 	ffilt := url.Values{}
 	ffilt.Set("status", "verzonden,deels_betaald,betaald")
-	ffilt.Set("datumNa", syncdate)
+	ffilt.Set("datumNa", opts.StartDate)
 	ffilt.Set("embed", "regels,Klant")
 	ffilt.Set("bedrijf_id", fmt.Sprintf("%d", b.ID))
-	facturen, err := rcl.GetFacturenFilter(ffilt)
+	facturen, err := rcl.GetFacturenFilter(ctx, ffilt)
 	if err != nil {
 		logentry.Debugf("Error fetching facturen: %s", err)
 		return err
 	}
-	logentry.WithField("#facturen", len(facturen)).WithField("startdatum", syncdate).Debug("Aantal facturen")
+	logentry.WithField("#facturen", len(facturen)).WithField("startdatum", opts.StartDate).Debug("Aantal facturen")
 	for _, f := range facturen {
-		err := syncFactuur(logentry.WithField("factuur", f.FactuurNummer), errc, rcl, ecl, f, pc, vatcodes)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var err error
+		if opts.BookingMode == BookSalesInvoices {
+			err = syncFactuurInvoice(ctx, logentry.WithField("factuur", f.FactuurNummer), errc, rcl, ecl, f, pc, vatcodes, bp, costs, dc)
+		} else {
+			err = syncFactuur(ctx, logentry.WithField("factuur", f.FactuurNummer), errc, rcl, ecl, f, pc, vatcodes, bp, costs, dc)
+		}
 		if err != nil {
-			errc <- errors.New("Fout bij het kopieren van factuur " + f.FactuurNummer + ": " + err.Error())
+			errc <- errors.New("Fout bij het kopieren van factuur " + f.FactuurNummer + ": " + httperror.Message(err))
+			continue
+		}
+		if f.Status == recras.StatusDeelsBetaald || f.Status == recras.StatusBetaald {
+			syncBetalingen(ctx, logentry.WithField("factuur", f.FactuurNummer), errc, rcl, ecl, opts, f)
 		}
 	}
 
@@ -97,54 +204,8 @@ func syncBedrijf(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 	return nil
 }
 
-func syncProducten(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client) error {
-	producten, err := rcl.GetAllProducten()
-	if err != nil {
-		logentry.Warnf("Error retrieving producten from Recras")
-		return err
-	}
-	for _, p := range producten {
-		_, err := syncProduct(logentry.WithField("RecrasProduct", p.ID), p, ecl)
-		if err != nil {
-			logentry.WithFields(logrus.Fields{
-				"RecrasProduct": p.ID,
-				"error":         err,
-			}).Warn("Error syncing Product")
-			errc <- err
-		}
-	}
-	return nil
-}
-
-func syncProduct(logentry *logrus.Entry, p recras.Product, ecl *exactonline.Client) (exactonline.Item, error) {
-	item, err := ecl.FindItemByRecrasID(p.ID)
-	if err == nil {
-		logentry.Info("Found item")
-		return item, nil
-	} else if _, ok := err.(exactonline.ErrItemNotFound); !ok {
-		return exactonline.Item{}, err
-	}
-	item.Code = fmt.Sprintf("recras%d", p.ID)
-	item.Description = fmt.Sprintf("Recras p%d: %s", p.ID, p.Naam)
-	item.StartDate = odata2json.Date{time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)}
-	item.IsSalesItem = true
-	item.Unit = "recras"
-	if err := item.Save(ecl); err != nil {
-		if e, ok := err.(httperror.HTTPError); ok {
-			bb := bytes.NewBuffer(nil)
-			io.Copy(bb, e.Response.Body)
-			bb2 := bytes.NewBuffer(nil)
-			io.Copy(bb2, e.Request.Body)
-			logentry.Debug("response" + bb.String())
-			logentry.Debug("request" + bb2.String())
-		}
-		return exactonline.Item{}, err
-	}
-	return item, nil
-}
-
-func syncFactuur(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, f recras.Factuur, pc exactonline.PaymentCondition, vatcodes exactonline.VATCodeList) error {
-	_, err := ecl.FindSalesEntry(f.FactuurNummer)
+func syncFactuur(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, f recras.Factuur, pc exactonline.PaymentCondition, vatcodes exactonline.VATCodeList, bp bookingPeriods, costs costAssigner, dc string) error {
+	_, err := ecl.FindSalesEntryByPaymentReference(ctx, f.FactuurNummer)
 	if err == nil { // SalesEntry found
 		logentry.Info("SalesEntry exists")
 		return nil
@@ -153,7 +214,14 @@ func syncFactuur(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		return err
 	}
 
-	lines, err := convertFactuurregels(ecl, f.Regels, 1, vatcodes)
+	date, err := bp.bookingDate(f)
+	if err != nil {
+		logentry.Info("Skipping: closed financial period")
+		errc <- err
+		return nil
+	}
+
+	lines, err := convertFactuurregels(ctx, ecl, f.Regels, 1, vatcodes, costs)
 	if err != nil {
 		logentry.Warnf("Skipping: Error converting factuurregels: %#v", err)
 		return err
@@ -164,24 +232,27 @@ func syncFactuur(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		return nil
 	}
 
-	cust, err := ecl.FindAccountByRecrasID(f.Klant.ID)
-	if _, ok := err.(exactonline.ErrAccountNotFound); ok {
-		_, e := syncKlant(logentry.WithField("klant", f.KlantID), errc, ecl, f.Klant)
-		if e != nil {
-			logentry.WithField("klant", f.KlantID).Warnf("Error saving Klant: %#v", err)
-			return e
-		}
-		cust, _ = ecl.FindAccountByRecrasID(f.Klant.ID)
-	} else if err != nil {
-		logentry.WithField("klant", f.KlantID).Warnf("Error finding Klant: %#v", err)
+	cust, err := findKlant(ctx, logentry, errc, ecl, f)
+	if err != nil {
 		return err
 	}
-	logentry.WithField("account", cust).Debug("Account")
 
 	entry := convertFactuur(ecl, cust, f, pc)
 	entry.SetSalesEntryLines(lines)
+	if !date.IsZero() {
+		entry.EntryDate.Time = date
+		entry.Description = movedDescription(entry.Description, f, date)
+	}
+	rate, err := exchangeRate(ctx, ecl, entry.Currency, dc, entry.EntryDate.Time)
+	if err != nil {
+		logentry.Warnf("Error retrieving exchange rate: %#v", err)
+		return err
+	}
+	if entry.Currency != "" && entry.Currency != dc {
+		entry.Rate = rate
+	}
 
-	pdf, err := uploadFactuurPDF(rcl, ecl, f, cust)
+	pdf, err := uploadFactuurPDF(ctx, rcl, ecl, f, cust)
 	if err != nil {
 		logentry.Warnf("Error uploading factuur PDF: %#v", err)
 	} else {
@@ -189,13 +260,12 @@ func syncFactuur(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		entry.Document = pdf.ID
 	}
 
-	if err := entry.Save(ecl); err != nil {
+	if err := entry.Save(ctx, ecl); err != nil {
 		if e, ok := err.(httperror.HTTPError); ok {
-			reqbody, _ := ioutil.ReadAll(e.Request.Body)
-			resbody, _ := ioutil.ReadAll(e.Response.Body)
 			logentry.WithFields(logrus.Fields{
-				"request body":  string(reqbody),
-				"response body": string(resbody),
+				"request body":  string(e.RequestBody),
+				"response body": string(e.Body),
+				"kind":          e.Kind().String(),
 				"salesentry":    entry,
 			}).Warnf("HTTP error saving factuur: %#v", e)
 		}
@@ -203,32 +273,181 @@ func syncFactuur(logentry *logrus.Entry, errc chan<- error, rcl *recras.Client,
 		return err
 	}
 	logentry.Info("Saved factuur")
+	if err := checkAmounts(f, dc, entry.AmountFC, entry.AmountDC, rate, len(lines)); err != nil {
+		logentry.Warn(err.Error())
+		errc <- err
+	}
+	return nil
+}
+
+func syncFactuurInvoice(ctx context.Context, logentry *logrus.Entry, errc chan<- error, rcl *recras.Client, ecl *exactonline.Client, f recras.Factuur, pc exactonline.PaymentCondition, vatcodes exactonline.VATCodeList, bp bookingPeriods, costs costAssigner, dc string) error {
+	existing, err := ecl.FindSalesInvoice(ctx, f.FactuurNummer)
+	if err == nil {
+		if existing.Status != exactonline.SalesInvoiceStatusDraft {
+			logentry.Info("SalesInvoice exists")
+			return nil
+		}
+		// an earlier sync created the draft but could not process it
+		logentry.Info("Processing draft salesinvoice")
+		lines, err := convertFactuurregelsToInvoiceLines(ctx, ecl, f.Regels, 1, vatcodes, costs)
+		if err != nil {
+			logentry.Warnf("Error converting factuurregels: %#v", err)
+			return err
+		}
+		return processSalesInvoice(ctx, logentry, errc, ecl, f, existing, dc, invoiceRate(existing.Currency, dc), len(lines))
+	} else if _, ok := err.(exactonline.ErrSalesInvoiceNotFound); !ok {
+		logentry.Warnf("Skipping: Error retrieving salesinvoice: %#v", err)
+		return err
+	}
+
+	date, err := bp.bookingDate(f)
+	if err != nil {
+		logentry.Info("Skipping: closed financial period")
+		errc <- err
+		return nil
+	}
+
+	lines, err := convertFactuurregelsToInvoiceLines(ctx, ecl, f.Regels, 1, vatcodes, costs)
+	if err != nil {
+		logentry.Warnf("Skipping: Error converting factuurregels: %#v", err)
+		return err
+	}
+	if len(lines) == 0 {
+		logentry.Info("Skipping: No lines with value")
+		errc <- errors.New(fmt.Sprintf("Skipping invoice %s, no lines with value", f.FactuurNummer))
+		return nil
+	}
+
+	cust, err := findKlant(ctx, logentry, errc, ecl, f)
+	if err != nil {
+		return err
+	}
+
+	invoice := convertFactuurToInvoice(cust, f, pc)
+	invoice.SetSalesInvoiceLines(lines)
+	if !date.IsZero() {
+		invoice.InvoiceDate.Time = date
+		invoice.Description = movedDescription(invoice.Description, f, date)
+	}
+
+	pdf, err := uploadFactuurPDF(ctx, rcl, ecl, f, cust)
+	if err != nil {
+		logentry.Warnf("Error uploading factuur PDF: %#v", err)
+	} else {
+		invoice.Document = pdf.ID
+	}
+
+	if err := invoice.Save(ctx, ecl); err != nil {
+		if e, ok := err.(httperror.HTTPError); ok {
+			logentry.WithFields(logrus.Fields{
+				"request body":  string(e.RequestBody),
+				"response body": string(e.Body),
+				"kind":          e.Kind().String(),
+			}).Warnf("HTTP error saving salesinvoice: %#v", e)
+		}
+		return err
+	}
+
+	return processSalesInvoice(ctx, logentry, errc, ecl, f, invoice, dc, invoiceRate(invoice.Currency, dc), len(lines))
+}
+
+// processSalesInvoice prints the draft invoice for f, which books it, and
+// checks the amounts of the draft against f, see checkAmounts
+func processSalesInvoice(ctx context.Context, logentry *logrus.Entry, errc chan<- error, ecl *exactonline.Client, f recras.Factuur, invoice exactonline.SalesInvoice, dc string, rate float64, lines int) error {
+	printed := exactonline.PrintedSalesInvoice{InvoiceID: invoice.ID}
+	if err := printed.Save(ctx, ecl); err != nil {
+		logentry.Warnf("Error processing salesinvoice: %#v", err)
+		return fmt.Errorf("factuur is als concept aangemaakt maar kon niet worden verwerkt: %s", httperror.Message(err))
+	}
+	logentry.Info("Saved and processed salesinvoice")
+	if err := checkAmounts(f, dc, invoice.AmountFC, invoice.AmountDC, rate, lines); err != nil {
+		logentry.Warn(err.Error())
+		errc <- err
+	}
 	return nil
 }
 
-func syncKlant(logentry *logrus.Entry, errc chan<- error, ecl *exactonline.Client, k recras.Klant) (exactonline.Account, error) {
+// findKlant returns the Account of the klant of f, creating it when needed
+func findKlant(ctx context.Context, logentry *logrus.Entry, errc chan<- error, ecl *exactonline.Client, f recras.Factuur) (exactonline.Account, error) {
+	cust, err := ecl.FindAccountByRecrasID(ctx, f.Klant.ID)
+	if _, ok := err.(exactonline.ErrAccountNotFound); ok {
+		_, e := syncKlant(ctx, logentry.WithField("klant", f.KlantID), errc, ecl, f.Klant)
+		if e != nil {
+			logentry.WithField("klant", f.KlantID).Warnf("Error saving Klant: %#v", err)
+			return exactonline.Account{}, e
+		}
+		cust, _ = ecl.FindAccountByRecrasID(ctx, f.Klant.ID)
+	} else if err != nil {
+		logentry.WithField("klant", f.KlantID).Warnf("Error finding Klant: %#v", err)
+		return exactonline.Account{}, err
+	}
+	logentry.WithField("account", cust).Debug("Account")
+	return cust, nil
+}
+
+func syncKlant(ctx context.Context, logentry *logrus.Entry, errc chan<- error, ecl *exactonline.Client, k recras.Klant) (exactonline.Account, error) {
 
-	a, err := ecl.FindAccountByRecrasID(k.ID)
+	a, err := ecl.FindAccountByRecrasID(ctx, k.ID)
 	if _, ok := err.(exactonline.ErrAccountNotFound); ok {
 		logentry.Info("Creating account")
-		a = exactonline.Account{
-			Name:         fmt.Sprintf("K%d %s", k.ID, k.Displaynaam),
-			AddressLine1: k.Adres,
-			Postcode:     k.Postcode,
-			City:         k.Plaats,
-			SearchCode:   fmt.Sprintf("K%d", k.ID),
-			Status:       "C",
+		a = convertKlant(k)
+		if err := a.Save(ctx, ecl); err != nil {
+			return exactonline.Account{}, err
 		}
-		if a.Name == "" {
-			a.Name = fmt.Sprintf("Recras K%d", k.ID)
+
+		if k.Achternaam != "" {
+			contact := exactonline.Contact{
+				Account:       a.ID,
+				FirstName:     k.Voornaam,
+				LastName:      k.Achternaam,
+				Email:         k.Email,
+				Phone:         k.Telefoon,
+				IsMainContact: true,
+			}
+			if err := contact.Save(ctx, ecl); err != nil {
+				logentry.Warnf("Error saving contact: %#v", err)
+				errc <- fmt.Errorf("Contactpersoon van klant K%d kon niet worden aangemaakt: %s", k.ID, httperror.Message(err))
+			}
 		}
-		if err := a.Save(ecl); err != nil {
-			return exactonline.Account{}, err
+		if k.Adres != "" {
+			address := exactonline.Address{
+				Account:      a.ID,
+				Type:         exactonline.AddressInvoice,
+				Main:         true,
+				AddressLine1: k.Adres,
+				Postcode:     k.Postcode,
+				City:         k.Plaats,
+				Country:      a.Country,
+			}
+			if err := address.Save(ctx, ecl); err != nil {
+				logentry.Warnf("Error saving invoice address: %#v", err)
+				errc <- fmt.Errorf("Factuuradres van klant K%d kon niet worden aangemaakt: %s", k.ID, httperror.Message(err))
+			}
 		}
 	}
 	return a, nil
 }
 
+func convertKlant(k recras.Klant) exactonline.Account {
+	a := exactonline.Account{
+		Name:         fmt.Sprintf("K%d %s", k.ID, k.Displaynaam),
+		AddressLine1: k.Adres,
+		Postcode:     k.Postcode,
+		City:         k.Plaats,
+		Country:      strings.ToUpper(k.Landcode),
+		Email:        k.Email,
+		Phone:        k.Telefoon,
+		Website:      k.Website,
+		SearchCode:   fmt.Sprintf("K%d", k.ID),
+		Status:       "C",
+		IsSales:      true,
+	}
+	if k.Displaynaam == "" {
+		a.Name = fmt.Sprintf("Recras K%d", k.ID)
+	}
+	return a
+}
+
 type ErrNoGLRevenueAccount struct {
 	ProductID int
 }
@@ -245,11 +464,11 @@ func (err ErrNoVATCode) Error() string {
 	return fmt.Sprintf("No VATCode specified for percentage %f", err.Percentage)
 }
 
-func convertFactuurregels(exact_itemfinder exactonline.ItemFinder, r []recras.Factuurregel, reductionfactor float64, vatcodes exactonline.VATCodeList) ([]exactonline.SalesEntryLine, error) {
+func convertFactuurregels(ctx context.Context, exact_itemfinder exactonline.ItemFinder, r []recras.Factuurregel, reductionfactor float64, vatcodes exactonline.VATCodeList, costs costAssigner) ([]exactonline.SalesEntryLine, error) {
 	out := []exactonline.SalesEntryLine{}
 	for _, regel := range r {
 		if regel.Type == recras.FactuurregelItem {
-			i, err := exact_itemfinder.FindItemByRecrasID(regel.ProductID)
+			i, err := exact_itemfinder.FindItemByRecrasID(ctx, regel.ProductID)
 			if err != nil {
 				return nil, err
 			}
@@ -273,9 +492,10 @@ func convertFactuurregels(exact_itemfinder exactonline.ItemFinder, r []recras.Fa
 				Quantity:    float64(regel.Aantal),
 				VATCode:     vc,
 			}
+			line.CostCenter, line.CostUnit = costs.assign(regel)
 			out = append(out, line)
 		} else if regel.Type == recras.FactuurregelGroep {
-			lines, err := convertFactuurregels(exact_itemfinder, regel.Regels, reductionfactor*(100-regel.Kortingspercentage)/100, vatcodes)
+			lines, err := convertFactuurregels(ctx, exact_itemfinder, regel.Regels, reductionfactor*(100-regel.Kortingspercentage)/100, vatcodes, costs)
 			if err != nil {
 				return nil, err
 			}
@@ -293,10 +513,11 @@ func convertFactuur(exact_itemfinder exactonline.ItemFinder, a exactonline.Accou
 		Customer:         a.ID,
 		YourRef:          f.ReferentieKlant,
 		PaymentCondition: pc.Code,
+		Currency:         factuurValuta(f),
 	}
 	if (f.Datum.Time != time.Time{}) {
 		entry.EntryDate.Time = f.Datum.Time
-		entry.DueDate.Time = entry.EntryDate.Time.AddDate(0, 0, f.Betaaltermijn)
+		entry.DueDate = odata2json.NullDate{Time: entry.EntryDate.Time.AddDate(0, 0, f.Betaaltermijn), Valid: true}
 	}
 	if f.CalculatedTotaalbedragInclusiefBTW < 0 {
 		entry.Type = exactonline.SalesEntryCreditNote
@@ -304,22 +525,84 @@ func convertFactuur(exact_itemfinder exactonline.ItemFinder, a exactonline.Accou
 	return entry
 }
 
-func uploadFactuurPDF(r *recras.Client, ecl *exactonline.Client, f recras.Factuur, ac exactonline.Account) (*exactonline.Document, error) {
+func convertFactuurregelsToInvoiceLines(ctx context.Context, exact_itemfinder exactonline.ItemFinder, r []recras.Factuurregel, reductionfactor float64, vatcodes exactonline.VATCodeList, costs costAssigner) ([]exactonline.SalesInvoiceLine, error) {
+	out := []exactonline.SalesInvoiceLine{}
+	for _, regel := range r {
+		if regel.Type == recras.FactuurregelItem {
+			factor := reductionfactor * (100 - regel.Kortingspercentage) / 100
+			if math.Abs(float64(regel.Aantal)*regel.Bedrag*factor) < 1e-3 {
+				continue
+			}
+			i, err := exact_itemfinder.FindItemByRecrasID(ctx, regel.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			vc, ok := vatcodes[regel.BTWPercentage]
+			if !ok {
+				return nil, ErrNoVATCode{Percentage: regel.BTWPercentage}
+			}
+			line := exactonline.SalesInvoiceLine{
+				Item:        i.ID,
+				Description: regel.Naam,
+				Quantity:    float64(regel.Aantal),
+				UnitPrice:   regel.Bedrag,
+				Discount:    1 - factor,
+				VATCode:     vc,
+			}
+			line.CostCenter, line.CostUnit = costs.assign(regel)
+			out = append(out, line)
+		} else if regel.Type == recras.FactuurregelGroep {
+			lines, err := convertFactuurregelsToInvoiceLines(ctx, exact_itemfinder, regel.Regels, reductionfactor*(100-regel.Kortingspercentage)/100, vatcodes, costs)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, lines...)
+		}
+	}
+	return out, nil
+}
+
+func convertFactuurToInvoice(a exactonline.Account, f recras.Factuur, pc exactonline.PaymentCondition) exactonline.SalesInvoice {
+	invoice := exactonline.SalesInvoice{
+		Description:      "Recras factuur: " + f.FactuurNummer,
+		PaymentReference: f.FactuurNummer,
+		Journal:          "recras",
+		OrderedBy:        a.ID,
+		YourRef:          f.ReferentieKlant,
+		PaymentCondition: pc.Code,
+		Type:             exactonline.SalesInvoiceTypeInvoice,
+		Currency:         factuurValuta(f),
+	}
+	if (f.Datum.Time != time.Time{}) {
+		invoice.InvoiceDate.Time = f.Datum.Time
+		invoice.DueDate = odata2json.NullDate{Time: f.Datum.Time.AddDate(0, 0, f.Betaaltermijn), Valid: true}
+	}
+	if f.CalculatedTotaalbedragInclusiefBTW < 0 {
+		invoice.Type = exactonline.SalesInvoiceTypeCreditNote
+	}
+	return invoice
+}
+
+func uploadFactuurPDF(ctx context.Context, r *recras.Client, ecl *exactonline.Client, f recras.Factuur, ac exactonline.Account) (*exactonline.Document, error) {
 	ret := new(exactonline.Document)
 	ret.Subject = "Recras factuur " + f.FactuurNummer
 
-	dt, err := ecl.FindDocumentTypeByDescription("Sales invoice")
+	dt, err := ecl.FindDocumentTypeByDescription(ctx, "Sales invoice")
 	if err != nil {
 		return nil, err
 	}
 	ret.Type = dt.ID
 
 	ret.Account = ac.ID
-	if err := ret.Save(ecl); err != nil {
+	if err := ret.Save(ctx, ecl); err != nil {
 		return nil, err
 	}
 
-	resp, err := r.Client.Get("/facturen/" + f.PdfLocatie)
+	req, err := http.NewRequestWithContext(ctx, "GET", "/facturen/"+f.PdfLocatie, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -335,7 +618,7 @@ func uploadFactuurPDF(r *recras.Client, ecl *exactonline.Client, f recras.Factuu
 		FileName:   f.PdfLocatie,
 		Attachment: bb.Bytes(),
 	}
-	da.Save(ecl)
+	da.Save(ctx, ecl)
 
 	return ret, nil
 }
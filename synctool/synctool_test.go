@@ -1,8 +1,10 @@
 package synctool
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/Recras/exactonline"
 	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
 	"golang.org/x/oauth2"
 )
 
@@ -26,12 +29,12 @@ var default_itemfinder = itemFinder{
 	GLRevenue:   "glaccount",
 }
 
-func (i *itemFinder) FindItemByRecrasID(recrasID int) (exactonline.Item, error) {
+func (i *itemFinder) FindItemByRecrasID(ctx context.Context, recrasID int) (exactonline.Item, error) {
 	return exactonline.Item(*i), nil
 }
 
 func Test_convertFactuurregel_Item(t *testing.T) {
-	lines, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{}, 1, exactonline.VATCodeList{})
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{}, 1, exactonline.VATCodeList{}, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error when converting empty slice")
 	}
@@ -39,7 +42,7 @@ func Test_convertFactuurregel_Item(t *testing.T) {
 		t.Errorf("Expected [], got %#v", lines)
 	}
 
-	lines, err = convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err = convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 0,
 		Aantal:             2,
@@ -49,7 +52,7 @@ func Test_convertFactuurregel_Item(t *testing.T) {
 		BTWPercentage:      21,
 	}}, 1, exactonline.VATCodeList{
 		21: "R21",
-	})
+	}, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error when converting single item")
 	}
@@ -75,7 +78,7 @@ func Test_convertFactuurregel_Item(t *testing.T) {
 }
 
 func Test_convertFactuurregel_NoVATCode(t *testing.T) {
-	_, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	_, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 0,
 		Aantal:             2,
@@ -85,7 +88,7 @@ func Test_convertFactuurregel_NoVATCode(t *testing.T) {
 		BTWPercentage:      21,
 	}}, 1, exactonline.VATCodeList{
 		6: "R21",
-	})
+	}, costAssigner{})
 	if _, ok := err.(ErrNoVATCode); !ok {
 		t.Errorf("Expected ErrNoVATCode, got %#v", err)
 	}
@@ -95,7 +98,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 	vatcodes := exactonline.VATCodeList{
 		21: "R21",
 	}
-	lines, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 0,
 		Aantal:             0,
@@ -103,7 +106,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		ProductID:          2,
 		Naam:               "Product",
 		BTWPercentage:      21,
-	}}, 1, vatcodes)
+	}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -111,7 +114,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		t.Errorf("Expected no lines with empty Aantal")
 	}
 
-	lines, err = convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err = convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 0,
 		Aantal:             1,
@@ -119,7 +122,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		ProductID:          2,
 		Naam:               "Product",
 		BTWPercentage:      21,
-	}}, 1, vatcodes)
+	}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -127,7 +130,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		t.Errorf("Expected no lines with empty Bedrag")
 	}
 
-	lines, err = convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err = convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 100,
 		Aantal:             1,
@@ -135,7 +138,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		ProductID:          2,
 		Naam:               "Product",
 		BTWPercentage:      21,
-	}}, 1, vatcodes)
+	}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -143,7 +146,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		t.Errorf("Expected no lines with full reduction")
 	}
 
-	lines, err = convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err = convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelItem,
 		Kortingspercentage: 0,
 		Aantal:             1,
@@ -151,7 +154,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		ProductID:          2,
 		Naam:               "Product",
 		BTWPercentage:      21,
-	}}, 0, vatcodes)
+	}}, 0, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -164,7 +167,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 		Code:      "recras12",
 		GLRevenue: "",
 	}
-	lines, err = convertFactuurregels(&itemf, []recras.Factuurregel{
+	lines, err = convertFactuurregels(context.Background(), &itemf, []recras.Factuurregel{
 		{
 			Type:               recras.FactuurregelItem,
 			ProductID:          12,
@@ -172,7 +175,7 @@ func Test_convertFactuurregel_ZeroItem(t *testing.T) {
 			Aantal:             1,
 			Bedrag:             0,
 		},
-	}, 1, exactonline.VATCodeList{})
+	}, 1, exactonline.VATCodeList{}, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -187,7 +190,7 @@ func Test_convertFactuurregel_noGLRevenue(t *testing.T) {
 		Code:      "recras12",
 		GLRevenue: "",
 	}
-	_, err := convertFactuurregels(&itemf, []recras.Factuurregel{
+	_, err := convertFactuurregels(context.Background(), &itemf, []recras.Factuurregel{
 		{
 			Type:               recras.FactuurregelItem,
 			ProductID:          12,
@@ -195,7 +198,7 @@ func Test_convertFactuurregel_noGLRevenue(t *testing.T) {
 			Aantal:             1,
 			Bedrag:             10,
 		},
-	}, 1, exactonline.VATCodeList{})
+	}, 1, exactonline.VATCodeList{}, costAssigner{})
 	if e, ok := err.(ErrNoGLRevenueAccount); !ok {
 		t.Errorf("Expected ErrNoGLRevenueAccount, got %#v", err)
 	} else if e.ProductID != 12 {
@@ -208,7 +211,7 @@ func Test_convertFactuurregel_MultipleItems(t *testing.T) {
 		6:  "R6",
 		21: "R21",
 	}
-	lines, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{
 		{
 			Type:               recras.FactuurregelItem,
 			Kortingspercentage: 0,
@@ -225,7 +228,7 @@ func Test_convertFactuurregel_MultipleItems(t *testing.T) {
 			ProductID:          4,
 			Naam:               "Product4",
 			BTWPercentage:      6,
-		}}, 1, vatcodes)
+		}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error when converting single item")
 	}
@@ -233,7 +236,7 @@ func Test_convertFactuurregel_MultipleItems(t *testing.T) {
 		t.Errorf("Expected # of SalesEntryLines to be 2, got %d", len(lines))
 	}
 
-	lines, err = convertFactuurregels(&default_itemfinder, []recras.Factuurregel{
+	lines, err = convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{
 		{
 			Type:               recras.FactuurregelItem,
 			Kortingspercentage: 0,
@@ -250,7 +253,7 @@ func Test_convertFactuurregel_MultipleItems(t *testing.T) {
 			ProductID:          4,
 			Naam:               "Product4",
 			BTWPercentage:      6,
-		}}, 1, vatcodes)
+		}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error when converting multiple item")
 	}
@@ -263,13 +266,13 @@ func Test_convertFactuurregel_NegativeItem(t *testing.T) {
 	vatcodes := exactonline.VATCodeList{
 		6: "R6",
 	}
-	lines, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:          recras.FactuurregelItem,
 		ProductID:     12,
 		Bedrag:        -10,
 		Aantal:        1,
 		BTWPercentage: 6,
-	}}, 1, vatcodes)
+	}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -284,7 +287,7 @@ func Test_convertFactuurregel_Group(t *testing.T) {
 	vatcodes := exactonline.VATCodeList{
 		6: "R6",
 	}
-	lines, err := convertFactuurregels(&default_itemfinder, []recras.Factuurregel{{
+	lines, err := convertFactuurregels(context.Background(), &default_itemfinder, []recras.Factuurregel{{
 		Type:               recras.FactuurregelGroep,
 		Kortingspercentage: 10,
 		Regels: []recras.Factuurregel{{
@@ -295,7 +298,7 @@ func Test_convertFactuurregel_Group(t *testing.T) {
 			Naam:          "Product4",
 			BTWPercentage: 6,
 		}},
-	}}, 1, vatcodes)
+	}}, 1, vatcodes, costAssigner{})
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -319,7 +322,7 @@ func Test_convertFactuur_simple(t *testing.T) {
 	}
 	factuur := recras.Factuur{
 		FactuurNummer:   "1-2-3",
-		Datum:           recras.Date{time.Date(2014, 7, 11, 0, 0, 0, 0, time.UTC)},
+		Datum:           recras.Date{Time: time.Date(2014, 7, 11, 0, 0, 0, 0, time.UTC)},
 		Betaaltermijn:   14,
 		ReferentieKlant: "asdf-123",
 	}
@@ -359,7 +362,7 @@ func Test_convertFactuur_negative(t *testing.T) {
 	factuur := recras.Factuur{
 		FactuurNummer:                      "1-2-3",
 		CalculatedTotaalbedragInclusiefBTW: -10,
-		Datum: recras.Date{time.Date(2014, 7, 11, 0, 0, 0, 0, time.UTC)},
+		Datum: recras.Date{Time: time.Date(2014, 7, 11, 0, 0, 0, 0, time.UTC)},
 	}
 	se := convertFactuur(&default_itemfinder, ac, factuur, exactonline.PaymentCondition{})
 	if se.Type != exactonline.SalesEntryCreditNote {
@@ -367,6 +370,29 @@ func Test_convertFactuur_negative(t *testing.T) {
 	}
 }
 
+func Test_convertKlant(t *testing.T) {
+	a := convertKlant(recras.Klant{
+		ID:          12,
+		Displaynaam: "Jan Jansen",
+		Email:       "jan@example.com",
+		Telefoon:    "0501234567",
+		Landcode:    "nl",
+	})
+	if a.Name != "K12 Jan Jansen" || a.SearchCode != "K12" {
+		t.Errorf("Expected name and searchcode to refer to the klant, got %#v", a)
+	}
+	if a.Email != "jan@example.com" || a.Phone != "0501234567" || a.Country != "NL" {
+		t.Errorf("Expected contact details to be copied, got %#v", a)
+	}
+	if !a.IsSales || a.Status != "C" {
+		t.Errorf("Expected account to be a customer, got %#v", a)
+	}
+
+	if a := convertKlant(recras.Klant{ID: 13}); a.Name != "Recras K13" {
+		t.Errorf("Expected fallback name, got %#v", a.Name)
+	}
+}
+
 func Test_uploadFactuurPDF_full(t *testing.T) {
 	factuurDownloaded := false
 	documentTypeAPICalled := false
@@ -420,8 +446,8 @@ func Test_uploadFactuurPDF_full(t *testing.T) {
 	defer ts.Close()
 
 	c := exactonline.Config{BaseURL: ts.URL}
-	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(3 * time.Second)})
-	cl.Division = 123
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl = cl.WithDivision(123)
 
 	r := recras.NewClient("test.recras.nl", "username", "password")
 	base, _ := url.Parse(ts.URL)
@@ -433,7 +459,7 @@ func Test_uploadFactuurPDF_full(t *testing.T) {
 		KlantID:       120,
 	}
 	a := exactonline.Account{ID: "account-guid"}
-	doc, err := uploadFactuurPDF(&r, cl, f, a)
+	doc, err := uploadFactuurPDF(context.Background(), &r, cl, f, a)
 	if !factuurDownloaded {
 		t.Errorf("Expected factuur.pdf to be downloaded")
 	}
@@ -470,3 +496,133 @@ func Test_uploadFactuurPDF_full(t *testing.T) {
 
 func Test_uploadFactuurPDF_fileNotFound(t *testing.T) {
 }
+
+func Test_convertFactuurregelsToInvoiceLines(t *testing.T) {
+	vatcodes := exactonline.VATCodeList{
+		21: "H21",
+	}
+	lines, err := convertFactuurregelsToInvoiceLines(context.Background(), &default_itemfinder, []recras.Factuurregel{{
+		Type:               recras.FactuurregelGroep,
+		Kortingspercentage: 50,
+		Regels: []recras.Factuurregel{{
+			Type:               recras.FactuurregelItem,
+			Aantal:             2,
+			Bedrag:             5,
+			ProductID:          4,
+			Naam:               "Product4",
+			BTWPercentage:      21,
+			Kortingspercentage: 20,
+		}, {
+			Type:          recras.FactuurregelItem,
+			Aantal:        3,
+			Bedrag:        0,
+			ProductID:     5,
+			Naam:          "Gratis",
+			BTWPercentage: 21,
+		}},
+	}}, 1, vatcodes, costAssigner{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %#v", lines)
+	}
+	l := lines[0]
+	if l.Item != default_itemfinder.ID || l.Quantity != 2 || l.UnitPrice != 5 || l.VATCode != "H21" {
+		t.Errorf("Unexpected line %#v", l)
+	}
+	if math.Abs(l.Discount-0.6) > 1e-9 {
+		t.Errorf("Expected the discounts of Groep and Item to add up to 0.6, got %f", l.Discount)
+	}
+}
+
+func Test_convertFactuurToInvoice(t *testing.T) {
+	ac := exactonline.Account{ID: "account-guid"}
+	factuur := recras.Factuur{
+		FactuurNummer:                      "1-2-3",
+		Datum:                              recras.Date{Time: time.Date(2014, 7, 11, 0, 0, 0, 0, time.UTC)},
+		Betaaltermijn:                      14,
+		CalculatedTotaalbedragInclusiefBTW: -10,
+	}
+	si := convertFactuurToInvoice(ac, factuur, exactonline.PaymentCondition{Code: "pcCode"})
+	if si.OrderedBy != ac.ID || si.Journal != "recras" || si.PaymentCondition != "pcCode" {
+		t.Errorf("Unexpected invoice %#v", si)
+	}
+	if si.DueDate.Time != factuur.Datum.Time.AddDate(0, 0, 14) {
+		t.Errorf("Expected DueDate to be %s, got %s", factuur.Datum.Time.AddDate(0, 0, 14), si.DueDate.Time)
+	}
+	if si.Type != exactonline.SalesInvoiceTypeCreditNote {
+		t.Errorf("Expected Type to be %d, got %d", exactonline.SalesInvoiceTypeCreditNote, si.Type)
+	}
+}
+
+func Test_bedrijfDivision_mapped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected a mapped bedrijf not to be looked up, got request to %s", r.URL)
+	}))
+	defer ts.Close()
+	c := exactonline.Config{BaseURL: ts.URL}
+	ecl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	ecl = ecl.WithDivision(1)
+
+	divisions := []exactonline.Division{{Code: 5, VATNumber: "NL123456789B01"}, {Code: 6, VATNumber: "NL987654321B01"}}
+	opts := Options{Divisions: map[int]int{7: 42}}
+	dcl, err := bedrijfDivision(ecl, opts, divisions, recras.Bedrijf{ID: 7, BTWNummer: "NL123456789B01"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if dcl.Division() != 42 || ecl.Division() != 1 {
+		t.Errorf("Expected division 42 and the original client to stay at 1, got %d and %d", dcl.Division(), ecl.Division())
+	}
+
+	dcl, err = bedrijfDivision(ecl, opts, divisions, recras.Bedrijf{ID: 8, BTWNummer: "nl 9876.54321 b01"})
+	if err != nil || dcl.Division() != 6 {
+		t.Errorf("Expected division 6 by BTWNummer, got %#v", err)
+	}
+	if _, err := bedrijfDivision(ecl, opts, divisions, recras.Bedrijf{ID: 9}); err != exactonline.ErrDivisionNotFound {
+		t.Errorf("Expected ErrDivisionNotFound without a BTWNummer, got %#v", err)
+	}
+}
+
+func Test_syncFactuurInvoice_draft(t *testing.T) {
+	printed := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/123/salesinvoice/SalesInvoices":
+			if r.Method != "GET" {
+				t.Errorf("Expected the draft not to be created again, got %s", r.Method)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"InvoiceID":"invoice-guid","PaymentReference":"1-2-3","Status":10,"AmountFC":121,"AmountDC":121}]}}`)
+		case "/api/v1/123/salesinvoice/PrintedSalesInvoices":
+			var p exactonline.PrintedSalesInvoice
+			json.NewDecoder(r.Body).Decode(&p)
+			if p.InvoiceID != "invoice-guid" {
+				t.Errorf("Expected the draft to be processed, got %#v", p)
+			}
+			printed++
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"InvoiceID":"invoice-guid","Status":50}}`)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := exactonline.Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl = cl.WithDivision(123)
+
+	errc := make(chan error, 10)
+	f := recras.Factuur{FactuurNummer: "1-2-3", CalculatedTotaalbedragInclusiefBTW: 121, Regels: []recras.Factuurregel{{}}}
+	err := syncFactuurInvoice(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, nil, cl, f, exactonline.PaymentCondition{}, nil, bookingPeriods{}, costAssigner{}, "EUR")
+	close(errc)
+	if err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	for err := range errc {
+		t.Errorf("Expected no errors, got %#v", err)
+	}
+	if printed != 1 {
+		t.Errorf("Expected the draft to be processed once, got %d", printed)
+	}
+}
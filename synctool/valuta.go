@@ -0,0 +1,82 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+)
+
+// factuurValuta returns the currency of f, or "" when Recras does not tell.
+// Exact Online books a factuur without currency in the division currency.
+func factuurValuta(f recras.Factuur) string {
+	return strings.ToUpper(strings.TrimSpace(f.Valuta))
+}
+
+// divisionValuta returns the currency of division, or "" when divisions does
+// not tell
+func divisionValuta(divisions []exactonline.Division, division int) string {
+	for _, d := range divisions {
+		if d.Code == division {
+			return strings.ToUpper(strings.TrimSpace(d.Currency))
+		}
+	}
+	return ""
+}
+
+// exchangeRate returns the rate to convert valuta into the division currency
+// dc with on date: 1 when they are the same or valuta is empty, or else the
+// rate from the exchange rates in Exact Online. It returns 0 to leave the
+// rate to Exact Online when dc is unknown or there is no rate.
+func exchangeRate(ctx context.Context, ecl *exactonline.Client, valuta, dc string, date time.Time) (float64, error) {
+	if valuta == "" || valuta == dc {
+		return 1, nil
+	}
+	if dc == "" {
+		return 0, nil
+	}
+	r, err := ecl.FindExchangeRate(ctx, valuta, dc, date)
+	if err == exactonline.ErrExchangeRateNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return r.Rate, nil
+}
+
+// invoiceRate returns the rate to check the DC total of a sales invoice in
+// valuta with. Exact Online converts sales invoices at a rate of its own, so
+// only invoices in the division currency dc are checked.
+func invoiceRate(valuta, dc string) float64 {
+	if valuta == "" || valuta == dc {
+		return 1
+	}
+	return 0
+}
+
+// checkAmounts compares the totals Exact Online calculated for f with the
+// Recras total, and the total in the division currency dc with the Recras
+// total at rate. Rounding may differ a cent per line. Totals Exact Online did
+// not return are not checked, nor is the DC total without a rate.
+func checkAmounts(f recras.Factuur, dc string, amountFC, amountDC, rate float64, lines int) error {
+	if amountFC == 0 && amountDC == 0 {
+		return nil
+	}
+	tolerance := 0.01 * math.Max(1, float64(lines))
+	valuta := factuurValuta(f)
+	if valuta == "" {
+		valuta = dc
+	}
+	total := f.CalculatedTotaalbedragInclusiefBTW
+	if math.Abs(amountFC-total) > tolerance {
+		return fmt.Errorf("Factuur %s is in Exact Online geboekt met totaal %s %.2f, in Recras is het %s %.2f", f.FactuurNummer, valuta, amountFC, valuta, total)
+	}
+	if rate != 0 && math.Abs(amountDC-total*rate) > tolerance {
+		return fmt.Errorf("Factuur %s is in Exact Online geboekt met totaal %s %.2f, bij koers %g is het %s %.2f", f.FactuurNummer, dc, amountDC, rate, dc, total*rate)
+	}
+	return nil
+}
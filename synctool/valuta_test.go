@@ -0,0 +1,108 @@
+package synctool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline"
+	"github.com/Recras/exactonline/recras"
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func Test_convertFactuur_valuta(t *testing.T) {
+	f := recras.Factuur{FactuurNummer: "1-2-3", Valuta: "gbp"}
+	entry := convertFactuur(&default_itemfinder, exactonline.Account{ID: "guid"}, f, exactonline.PaymentCondition{Code: "RC"})
+	if entry.Currency != "GBP" {
+		t.Errorf("Expected Currency to be GBP, got %#v", entry.Currency)
+	}
+	f.Valuta = ""
+	if entry := convertFactuur(&default_itemfinder, exactonline.Account{ID: "guid"}, f, exactonline.PaymentCondition{Code: "RC"}); entry.Currency != "" {
+		t.Errorf("Expected facturen without valuta to be left in the division currency, got %#v", entry.Currency)
+	}
+}
+
+func Test_divisionValuta(t *testing.T) {
+	divisions := []exactonline.Division{{Code: 1, Currency: "EUR"}, {Code: 2, Currency: "gbp"}}
+	if v := divisionValuta(divisions, 2); v != "GBP" {
+		t.Errorf("Expected GBP, got %#v", v)
+	}
+	if v := divisionValuta(divisions, 3); v != "" {
+		t.Errorf("Expected an unknown division to have no currency, got %#v", v)
+	}
+}
+
+func Test_checkAmounts(t *testing.T) {
+	eur := recras.Factuur{FactuurNummer: "1-2-3", CalculatedTotaalbedragInclusiefBTW: 121}
+	if err := checkAmounts(eur, "EUR", 121, 121, 1, 2); err != nil {
+		t.Errorf("Expected matching euro amounts to pass, got %s", err)
+	}
+	if err := checkAmounts(eur, "EUR", 0, 0, 1, 2); err != nil {
+		t.Errorf("Expected missing totals not to be checked, got %s", err)
+	}
+	if err := checkAmounts(eur, "EUR", 120, 120, 1, 2); err == nil {
+		t.Errorf("Expected a different FC total to be reported")
+	}
+
+	credit := recras.Factuur{FactuurNummer: "1-2-4", CalculatedTotaalbedragInclusiefBTW: -100, Valuta: "GBP"}
+	if err := checkAmounts(credit, "EUR", -100, -125, 1.25, 1); err != nil {
+		t.Errorf("Expected the DC total at the rate to pass, got %s", err)
+	}
+	if err := checkAmounts(credit, "EUR", 100, 125, 1.25, 1); err == nil {
+		t.Errorf("Expected a credit note booked as an invoice to be reported")
+	}
+	if err := checkAmounts(credit, "EUR", -100, -100, 1.25, 1); err == nil {
+		t.Errorf("Expected a DC total booked at another rate to be reported")
+	}
+	if err := checkAmounts(credit, "", -100, -100, 0, 1); err != nil {
+		t.Errorf("Expected the DC total not to be checked without a rate, got %s", err)
+	}
+}
+
+func Test_syncFactuurInvoice_valuta(t *testing.T) {
+	for valuta, expected := range map[string]int{"EUR": 1, "GBP": 0} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/123/salesinvoice/SalesInvoices":
+				fmt.Fprintf(w, `{"d":{"results":[{"InvoiceID":"invoice-guid","PaymentReference":"1-2-3","Status":10,"InvoiceDate":"/Date(1420156800000)/","Currency":"%s","AmountFC":100,"AmountDC":90}]}}`, valuta)
+			case "/api/v1/123/salesinvoice/PrintedSalesInvoices":
+				w.WriteHeader(201)
+				fmt.Fprint(w, `{"d":{"InvoiceID":"invoice-guid","Status":50}}`)
+			default:
+				t.Errorf("Unexpected path %#v", r.URL.Path)
+			}
+		}))
+
+		c := exactonline.Config{BaseURL: ts.URL}
+		cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)}).WithDivision(123)
+
+		errc := make(chan error, 10)
+		f := recras.Factuur{FactuurNummer: "1-2-3", CalculatedTotaalbedragInclusiefBTW: 100, Valuta: valuta, Regels: []recras.Factuurregel{{}}}
+		err := syncFactuurInvoice(context.Background(), logrus.NewEntry(logrus.StandardLogger()), errc, nil, cl, f, exactonline.PaymentCondition{}, nil, bookingPeriods{}, costAssigner{}, "EUR")
+		close(errc)
+		ts.Close()
+		if err != nil {
+			t.Errorf("%s: Expected no error, got %#v", valuta, err)
+		}
+		errs := []error{}
+		for err := range errc {
+			errs = append(errs, err)
+		}
+		if len(errs) != expected {
+			t.Errorf("%s: Expected %d errors, the DC total is only checked in the division currency, got %#v", valuta, expected, errs)
+		}
+	}
+}
+
+func Test_exchangeRate(t *testing.T) {
+	if r, err := exchangeRate(context.Background(), nil, "", "GBP", time.Now()); err != nil || r != 1 {
+		t.Errorf("Expected a factuur without valuta to be booked at 1, got %g and %#v", r, err)
+	}
+	if r, err := exchangeRate(context.Background(), nil, "GBP", "", time.Now()); err != nil || r != 0 {
+		t.Errorf("Expected the rate to be left to Exact Online without a division currency, got %g and %#v", r, err)
+	}
+}
@@ -0,0 +1,141 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const transactionLinesURI = "/api/v1/%d/financialtransaction/TransactionLines"
+
+// TransactionLine is a single line of a booked financial transaction
+type TransactionLine struct {
+	ID               string
+	Account          string
+	AccountCode      string
+	AmountDC         float64
+	AmountFC         float64
+	AmountVATFC      float64
+	Currency         string
+	Date             odata2json.Date
+	Description      string
+	EntryID          string
+	EntryNumber      int
+	FinancialPeriod  int
+	FinancialYear    int
+	GLAccount        string
+	GLAccountCode    string
+	InvoiceNumber    int
+	JournalCode      string
+	LineNumber       int
+	PaymentReference string
+	VATCode          string
+	VATPercentage    float64
+	YourRef          string
+}
+
+// TransactionLineQuery selects transaction lines. Fields left empty do not
+// filter; From and To are dates and both are included.
+type TransactionLineQuery struct {
+	JournalCode   string
+	From          time.Time
+	To            time.Time
+	GLAccountCode string
+	EntryNumber   int
+}
+
+// Filter returns the OData filter for q
+func (q TransactionLineQuery) Filter() Filter {
+	fs := []Filter{}
+	if q.JournalCode != "" {
+		fs = append(fs, Eq("JournalCode", q.JournalCode))
+	}
+	if !q.From.IsZero() {
+		fs = append(fs, Ge("Date", truncateDate(q.From)))
+	}
+	if !q.To.IsZero() {
+		fs = append(fs, Le("Date", truncateDate(q.To)))
+	}
+	if q.GLAccountCode != "" {
+		fs = append(fs, Eq("GLAccountCode", q.GLAccountCode))
+	}
+	if q.EntryNumber != 0 {
+		fs = append(fs, Eq("EntryNumber", q.EntryNumber))
+	}
+	return And(fs...)
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GetTransactionLines returns the transaction lines matching q ordered by
+// Date, EntryNumber and LineNumber, following the pages Exact Online returns
+func (c *Client) GetTransactionLines(ctx context.Context, q TransactionLineQuery) ([]TransactionLine, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []TransactionLine{}
+	u := NewQuery().Filter(q.Filter()).
+		OrderBy("Date").OrderBy("EntryNumber").OrderBy("LineNumber").
+		URL(fmt.Sprintf(transactionLinesURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DayTotal sums up the sales booked on a single day in the division
+// currency. Total includes VAT, like the Recras invoice totals.
+type DayTotal struct {
+	Date    time.Time
+	Total   float64
+	Revenue float64
+	VAT     float64
+}
+
+// SumTransactionLinesPerDay sums sales entry lines per day, ordered by date.
+// The total is taken from the customer lines, which have LineNumber 0, and
+// the VAT from the VAT amounts on the other lines.
+func SumTransactionLinesPerDay(lines []TransactionLine) []DayTotal {
+	days := map[time.Time]*DayTotal{}
+	for _, l := range lines {
+		d, ok := days[l.Date.Time]
+		if !ok {
+			d = &DayTotal{Date: l.Date.Time}
+			days[l.Date.Time] = d
+		}
+		if l.LineNumber == 0 {
+			d.Total += l.AmountDC
+			continue
+		}
+		vat := l.AmountVATFC
+		if l.AmountFC != 0 {
+			vat = vat * l.AmountDC / l.AmountFC
+		}
+		d.VAT -= vat
+	}
+
+	out := make([]DayTotal, 0, len(days))
+	for _, d := range days {
+		d.Revenue = d.Total - d.VAT
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date)
+	})
+	return out
+}
+
+// SumRecrasJournalPerDay returns the sales booked on the recras journal per
+// day from the date from up to and including to
+func (c *Client) SumRecrasJournalPerDay(ctx context.Context, from, to time.Time) ([]DayTotal, error) {
+	lines, err := c.GetTransactionLines(ctx, TransactionLineQuery{JournalCode: "recras", From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	return SumTransactionLinesPerDay(lines), nil
+}
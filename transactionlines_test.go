@@ -0,0 +1,93 @@
+package exactonline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline/odata2json"
+	"golang.org/x/oauth2"
+)
+
+func TestTransactionLineQuery_Filter(t *testing.T) {
+	q := TransactionLineQuery{
+		JournalCode:   "recras",
+		From:          time.Date(2015, 1, 2, 13, 0, 0, 0, time.UTC),
+		To:            time.Date(2015, 1, 31, 0, 0, 0, 0, time.UTC),
+		GLAccountCode: "8000",
+		EntryNumber:   15000001,
+	}
+	expected := `(JournalCode eq 'recras') and (Date ge datetime'2015-01-02T00:00:00') and (Date le datetime'2015-01-31T00:00:00') and (GLAccountCode eq '8000') and (EntryNumber eq 15000001)`
+	if f := string(q.Filter()); f != expected {
+		t.Errorf("Expected filter %s, got %s", expected, f)
+	}
+	if f := (TransactionLineQuery{}).Filter(); f != "" {
+		t.Errorf("Expected an empty query not to filter, got %s", f)
+	}
+}
+
+func TestGetTransactionLines(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.Path; p != "/api/v1/123/financialtransaction/TransactionLines" {
+			t.Errorf("Expected path to be `/api/v1/123/financialtransaction/TransactionLines`, got `%s`", p)
+		}
+		if r.URL.Query().Get("$skiptoken") == "" {
+			q := r.URL.Query()
+			if f := q.Get("$filter"); f != "JournalCode eq 'recras'" {
+				t.Errorf("Unexpected $filter %#v", f)
+			}
+			if o := q.Get("$orderby"); o != "Date,EntryNumber,LineNumber" {
+				t.Errorf("Unexpected $orderby %#v", o)
+			}
+			fmt.Fprintf(w, `{"d":{"results":[{"ID":"guid1","EntryNumber":1}],"__next":"%s/api/v1/123/financialtransaction/TransactionLines?$skiptoken=1"}}`, ts.URL)
+			return
+		}
+		fmt.Fprint(w, `{"d":{"results":[{"ID":"guid2","EntryNumber":2}]}}`)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	lines, err := cl.GetTransactionLines(context.Background(), TransactionLineQuery{JournalCode: "recras"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(lines) != 2 || lines[0].ID != "guid1" || lines[1].ID != "guid2" {
+		t.Errorf("Expected lines of both pages, got %#v", lines)
+	}
+}
+
+func TestSumTransactionLinesPerDay(t *testing.T) {
+	jan2 := odata2json.Date{Time: time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)}
+	jan1 := odata2json.Date{Time: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)}
+	lines := []TransactionLine{
+		{Date: jan2, EntryNumber: 1, LineNumber: 0, AmountDC: 121, AmountFC: 121},
+		{Date: jan2, EntryNumber: 1, LineNumber: 1, AmountDC: -100, AmountFC: -100, AmountVATFC: -21},
+		{Date: jan2, EntryNumber: 2, LineNumber: 0, AmountDC: 125, AmountFC: 100},
+		{Date: jan2, EntryNumber: 2, LineNumber: 1, AmountDC: -125, AmountFC: -100, AmountVATFC: 0},
+		{Date: jan1, EntryNumber: 3, LineNumber: 0, AmountDC: 109, AmountFC: 109},
+		{Date: jan1, EntryNumber: 3, LineNumber: 1, AmountDC: -50, AmountFC: -50, AmountVATFC: -4.5},
+		{Date: jan1, EntryNumber: 3, LineNumber: 2, AmountDC: -50, AmountFC: -50, AmountVATFC: -4.5},
+	}
+	days := SumTransactionLinesPerDay(lines)
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %#v", days)
+	}
+	expected := []DayTotal{
+		{Date: jan1.Time, Total: 109, Revenue: 100, VAT: 9},
+		{Date: jan2.Time, Total: 246, Revenue: 225, VAT: 21},
+	}
+	for i, e := range expected {
+		d := days[i]
+		if d.Date != e.Date || math.Abs(d.Total-e.Total) > 1e-9 || math.Abs(d.Revenue-e.Revenue) > 1e-9 || math.Abs(d.VAT-e.VAT) > 1e-9 {
+			t.Errorf("Expected %#v, got %#v", e, d)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+const unitURI = "/api/v1/%d/logistics/Units"
+
+// Unit is a unit Items are sold in, referred to by Code from Item.Unit
+type Unit struct {
+	ID          string `json:",omitempty"`
+	Code        string
+	Description string
+	Type        string `json:",omitempty"`
+	Active      bool   `json:",omitempty"`
+}
+
+const (
+	UnitTypeOther = "O"
+	UnitTypeTime  = "T"
+)
+
+type ErrUnitNotFound struct {
+	Division int
+	Code     string
+}
+
+func (e ErrUnitNotFound) Error() string {
+	return fmt.Sprintf("Unit not found for Code `%s` in Division %d", e.Code, e.Division)
+}
+
+func (c *Client) GetUnits(ctx context.Context) ([]Unit, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	out := []Unit{}
+	if err := c.Iterate(ctx, fmt.Sprintf(unitURI, c.division)).All(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) FindUnitByCode(ctx context.Context, code string) (Unit, error) {
+	if c.division == 0 {
+		return Unit{}, ErrNoDivision
+	}
+	out := []Unit{}
+	u := NewQuery().Filter(Eq("Code", code)).URL(fmt.Sprintf(unitURI, c.division))
+	if err := c.Iterate(ctx, u).All(&out); err != nil {
+		return Unit{}, err
+	}
+	if len(out) == 0 {
+		return Unit{}, ErrUnitNotFound{c.division, code}
+	}
+	return out[0], nil
+}
+
+var (
+	ErrUnitCodeRequired        = errors.New("Field `Code` on type `Unit` is mandatory")
+	ErrUnitDescriptionRequired = errors.New("Field `Description` on type `Unit` is mandatory")
+)
+
+func (u *Unit) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if u.Code == "" {
+		return ErrUnitCodeRequired
+	}
+	if u.Description == "" {
+		return ErrUnitDescriptionRequired
+	}
+
+	bs, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(unitURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]Unit{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*u = envelope["d"]
+	return nil
+}
@@ -0,0 +1,149 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Recras/exactonline/httperror"
+)
+
+var ErrIDRequired = errors.New("exactonline: an entity without ID cannot be updated or deleted")
+
+// ErrConcurrency is returned when an entity was changed by someone else since
+// it was read
+type ErrConcurrency struct {
+	httperror.HTTPError
+}
+
+func (e ErrConcurrency) Error() string {
+	return "exactonline: entity was changed in the meantime: " + e.HTTPError.Error()
+}
+
+// ErrInUse is returned when an entity cannot be changed or deleted because
+// other records refer to it
+type ErrInUse struct {
+	httperror.HTTPError
+}
+
+func (e ErrInUse) Error() string {
+	return "exactonline: entity is in use: " + e.HTTPError.Error()
+}
+
+func entityError(resp *http.Response) error {
+	e := httperror.New(resp)
+	msg := strings.ToLower(e.Message)
+	switch {
+	case e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusPreconditionFailed:
+		return ErrConcurrency{e}
+	case strings.Contains(msg, "in use") || strings.Contains(msg, "in gebruik"):
+		return ErrInUse{e}
+	}
+	return e
+}
+
+func entityURI(uri string, division int, id string) string {
+	return fmt.Sprintf(uri, division) + "(guid" + quote(id) + ")"
+}
+
+// changedFields returns the json fields of updated that differ from orig.
+// Fields left out of updated by omitempty are sent as their zero value, so
+// Exact Online clears them.
+func changedFields(orig, updated interface{}, skip ...string) (map[string]json.RawMessage, error) {
+	var o, u map[string]json.RawMessage
+	bs, err := json.Marshal(orig)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &o); err != nil {
+		return nil, err
+	}
+	bs, err = json.Marshal(updated)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &u); err != nil {
+		return nil, err
+	}
+
+	out := map[string]json.RawMessage{}
+	for k, v := range u {
+		if ov, ok := o[k]; !ok || !bytes.Equal(ov, v) {
+			out[k] = v
+		}
+	}
+	for k := range o {
+		if _, ok := u[k]; !ok {
+			out[k] = zeroJSON(o[k])
+		}
+	}
+	for _, k := range skip {
+		delete(out, k)
+	}
+	return out, nil
+}
+
+// zeroJSON returns the zero value of the type of json value v. Strings and
+// objects are cleared with null.
+func zeroJSON(v json.RawMessage) json.RawMessage {
+	switch s := string(bytes.TrimSpace(v)); {
+	case s == "true" || s == "false":
+		return json.RawMessage("false")
+	case len(s) > 0 && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')):
+		return json.RawMessage("0")
+	}
+	return json.RawMessage("null")
+}
+
+// update sends the fields of updated that differ from orig to the entity
+// with id. No request is made when nothing changed.
+func (c *Client) update(ctx context.Context, uri, id string, orig, updated interface{}, skip ...string) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if id == "" {
+		return ErrIDRequired
+	}
+	fields, err := changedFields(orig, updated, skip...)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	bs, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	resp, err := c.put(ctx, entityURI(uri, c.division, id), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 204 && resp.StatusCode != 200 {
+		return entityError(resp)
+	}
+	return nil
+}
+
+func (c *Client) remove(ctx context.Context, uri, id string) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if id == "" {
+		return ErrIDRequired
+	}
+	resp, err := c.delete(ctx, entityURI(uri, c.division, id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 204 && resp.StatusCode != 200 {
+		return entityError(resp)
+	}
+	return nil
+}
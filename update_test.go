@@ -0,0 +1,154 @@
+package exactonline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestChangedFields(t *testing.T) {
+	orig := Account{ID: "guid", Name: "Recras", City: "Groningen", Postcode: "9700 AA", Blocked: true}
+	updated := orig
+	updated.City = "Zwolle"
+	updated.Postcode = ""
+	updated.Blocked = false
+
+	fields, err := changedFields(orig, updated, "ID")
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(fields) != 3 {
+		t.Errorf("Expected 3 changed fields, got %#v", fields)
+	}
+	if string(fields["City"]) != `"Zwolle"` {
+		t.Errorf("Expected City to be changed, got %s", fields["City"])
+	}
+	if string(fields["Postcode"]) != `null` {
+		t.Errorf("Expected emptied Postcode to be cleared, got %s", fields["Postcode"])
+	}
+	if string(fields["Blocked"]) != `false` {
+		t.Errorf("Expected Blocked to be set to false, got %s", fields["Blocked"])
+	}
+}
+
+func TestAccountUpdate(t *testing.T) {
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			t.Errorf("Expected PUT, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v1/123/crm/Accounts(guid'account-guid')" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	orig := Account{ID: "account-guid", Name: "Recras", City: "Groningen"}
+	a := orig
+	a.Name = "Recras B.V."
+	if err := a.Update(context.Background(), cl, orig); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if len(body) != 1 || body["Name"] != "Recras B.V." {
+		t.Errorf("Expected only the changed Name to be sent, got %#v", body)
+	}
+
+	body = nil
+	if err := a.Update(context.Background(), cl, a); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if body != nil {
+		t.Errorf("Expected no request without changes, got %#v", body)
+	}
+
+	a.ID = ""
+	if err := a.Update(context.Background(), cl, orig); err != ErrIDRequired {
+		t.Errorf("Expected ErrIDRequired, got %#v", err)
+	}
+}
+
+func TestSalesEntryUpdate_skipsLines(t *testing.T) {
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/123/salesentry/SalesEntries(guid'entry-guid')" {
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	orig := SalesEntry{ID: "entry-guid", Description: "Recras factuur: 1"}
+	s := orig
+	s.Description = "Recras factuur: 1 (gecorrigeerd)"
+	s.SetSalesEntryLines([]SalesEntryLine{{AmountFC: 10}})
+	if err := s.Update(context.Background(), cl, orig); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if _, ok := body["SalesEntryLines"]; ok {
+		t.Errorf("Expected SalesEntryLines not to be sent, got %#v", body)
+	}
+	if len(body) != 1 {
+		t.Errorf("Expected only Description to be sent, got %#v", body)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Expected DELETE, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/api/v1/123/logistics/Items(guid'item-guid')":
+			w.WriteHeader(204)
+		case "/api/v1/123/logistics/Items(guid'used-guid')":
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"error":{"code":"","message":{"lang":"en-US","value":"Item is in use"}}}`)
+		case "/api/v1/123/logistics/Items(guid'changed-guid')":
+			w.WriteHeader(412)
+		default:
+			t.Errorf("Unexpected path %#v", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	i := Item{ID: "item-guid"}
+	if err := i.Delete(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	i.ID = "used-guid"
+	if err, ok := i.Delete(context.Background(), cl).(ErrInUse); !ok {
+		t.Errorf("Expected ErrInUse, got %#v", err)
+	}
+	i.ID = "changed-guid"
+	if err, ok := i.Delete(context.Background(), cl).(ErrConcurrency); !ok {
+		t.Errorf("Expected ErrConcurrency, got %#v", err)
+	}
+
+	if err := (&Item{}).Delete(context.Background(), cl); err != ErrIDRequired {
+		t.Errorf("Expected ErrIDRequired, got %#v", err)
+	}
+	if err := (&Item{ID: "item-guid"}).Delete(context.Background(), &Client{}); err != ErrNoDivision {
+		t.Errorf("Expected ErrNoDivision, got %#v", err)
+	}
+}
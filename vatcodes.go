@@ -1,9 +1,11 @@
 package exactonline
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/url"
 
 	"github.com/Recras/exactonline/httperror"
 )
@@ -12,40 +14,90 @@ type VATCodeList map[float64]string
 
 const vatCodeURI = "/api/v1/%d/vat/VATCodes"
 
+// VATCode is a VAT code of a division. Percentage is a fraction, 0.21 for 21%.
+// GLToPay and GLToClaim are the GL accounts the VAT is booked on.
 type VATCode struct {
-	ID          string
-	Code        string
-	Description string
+	ID                 string `json:",omitempty"`
+	Code               string
+	Description        string
+	Percentage         float64
+	Type               string `json:",omitempty"`
+	VATTransactionType string `json:",omitempty"`
+	GLToPay            string `json:",omitempty"`
+	GLToClaim          string `json:",omitempty"`
+	IsBlocked          bool   `json:",omitempty"`
 }
 
-type vatCodes struct {
-	D struct {
-		Results []VATCode `json:"results"`
-	} `json:"d"`
-}
+const (
+	VATCodeTypeExclusive = "E"
+	VATCodeTypeInclusive = "I"
+
+	VATTransactionTypeSales    = "S"
+	VATTransactionTypePurchase = "P"
+	VATTransactionTypeBoth     = "B"
+)
 
-func (c *Client) GetRecrasVATCodes() ([]VATCode, error) {
-	if c.Division == 0 {
+// GetVATCodes returns the VAT codes matching f, or all of them when f is
+// empty
+func (c *Client) GetVATCodes(ctx context.Context, f Filter) ([]VATCode, error) {
+	if c.division == 0 {
 		return nil, ErrNoDivision
 	}
-	filt := url.Values{}
-	filt.Set("$filter", "substringof('recras:', Description) eq true")
-	u := fmt.Sprintf(vatCodeURI+"?%s", c.Division, filt.Encode())
-	resp, err := c.Client.Get(u)
+	u := NewQuery().Filter(f).URL(fmt.Sprintf(vatCodeURI, c.division))
+	out := []VATCode{}
+	err := c.Iterate(ctx, u).All(&out)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return nil, httperror.New(resp)
+	return out, nil
+}
+
+func (c *Client) GetRecrasVATCodes(ctx context.Context) ([]VATCode, error) {
+	return c.GetVATCodes(ctx, SubstringOf("recras:", "Description"))
+}
+
+var (
+	ErrVATCodeCodeRequired        = errors.New("Field `Code` on type `VATCode` is mandatory")
+	ErrVATCodeDescriptionRequired = errors.New("Field `Description` on type `VATCode` is mandatory")
+)
+
+func (v *VATCode) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if v.Code == "" {
+		return ErrVATCodeCodeRequired
+	}
+	if v.Description == "" {
+		return ErrVATCodeDescriptionRequired
 	}
 
-	out := &vatCodes{}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(vatCodeURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
 
+	envelope := map[string]VATCode{}
 	dec := json.NewDecoder(resp.Body)
-	err = dec.Decode(out)
-	if err != nil {
-		return nil, err
+	if err := dec.Decode(&envelope); err != nil {
+		return err
 	}
+	*v = envelope["d"]
+	return nil
+}
+
+func (v *VATCode) Update(ctx context.Context, c *Client, orig VATCode) error {
+	return c.update(ctx, vatCodeURI, v.ID, orig, *v, "ID")
+}
 
-	return out.D.Results, nil
+func (v *VATCode) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, vatCodeURI, v.ID)
 }
@@ -1,6 +1,7 @@
 package exactonline
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -24,10 +25,10 @@ func TestGetRecrasVATCodes(t *testing.T) {
 	defer ts.Close()
 	c := Config{BaseURL: ts.URL}
 	cl := c.NewClient(oauth2.Token{
-		Expiry: time.Now().Add(1 * time.Second),
+		Expiry: time.Now().Add(time.Minute),
 	})
-	cl.Division = 123
-	items, err := cl.GetRecrasVATCodes()
+	cl.division = 123
+	items, err := cl.GetRecrasVATCodes(context.Background())
 	if err != nil {
 		t.Errorf("Expected no error, got %#v", err)
 	}
@@ -40,7 +41,7 @@ func TestGetRecrasVATCodes(t *testing.T) {
 
 func TestGetRecrasVATCodes_NoDivision(t *testing.T) {
 	cl := Client{}
-	_, err := cl.GetRecrasVATCodes()
+	_, err := cl.GetRecrasVATCodes(context.Background())
 	if err != ErrNoDivision {
 		t.Errorf("Expected ErrNoDivision, got %#v", err)
 	}
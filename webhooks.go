@@ -0,0 +1,199 @@
+package exactonline
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Recras/exactonline/httperror"
+	"github.com/Recras/exactonline/odata2json"
+)
+
+const webhookSubscriptionURI = "/api/v1/%d/webhooks/WebhookSubscriptions"
+
+// WebhookSubscription makes Exact Online call CallbackURL whenever an entity
+// of Topic changes, Topic being the name of the entity set, e.g. SalesEntries
+type WebhookSubscription struct {
+	ID          string `json:",omitempty"`
+	CallbackURL string
+	Topic       string
+	Description string `json:",omitempty"`
+}
+
+type webhookSubscriptions struct {
+	D struct {
+		Results []WebhookSubscription `json:"results"`
+	} `json:"d"`
+}
+
+var (
+	ErrWebhookSubscriptionCallbackURLRequired = errors.New("Field `CallbackURL` on type `WebhookSubscription` is mandatory")
+	ErrWebhookSubscriptionTopicRequired       = errors.New("Field `Topic` on type `WebhookSubscription` is mandatory")
+)
+
+func (c *Client) GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	if c.division == 0 {
+		return nil, ErrNoDivision
+	}
+	resp, err := c.get(ctx, fmt.Sprintf(webhookSubscriptionURI, c.division))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, httperror.New(resp)
+	}
+
+	out := &webhookSubscriptions{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(out); err != nil {
+		return nil, err
+	}
+	return out.D.Results, nil
+}
+
+func (s *WebhookSubscription) Save(ctx context.Context, c *Client) error {
+	if c.division == 0 {
+		return ErrNoDivision
+	}
+	if s.CallbackURL == "" {
+		return ErrWebhookSubscriptionCallbackURLRequired
+	}
+	if s.Topic == "" {
+		return ErrWebhookSubscriptionTopicRequired
+	}
+
+	bs, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, fmt.Sprintf(webhookSubscriptionURI, c.division), bytes.NewBuffer(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 201 {
+		return httperror.New(resp)
+	}
+
+	envelope := map[string]WebhookSubscription{}
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&envelope); err != nil {
+		return err
+	}
+	*s = envelope["d"]
+	return nil
+}
+
+func (s *WebhookSubscription) Delete(ctx context.Context, c *Client) error {
+	return c.remove(ctx, webhookSubscriptionURI, s.ID)
+}
+
+// WebhookEvent is the content of a webhook call. Key is the ID of the
+// changed entity, Action is Create, Update or Delete.
+type WebhookEvent struct {
+	Topic               string
+	ClientID            string `json:"ClientId"`
+	Division            int
+	Action              string
+	Key                 string
+	ExactOnlineEndpoint string
+	EventCreatedOn      odata2json.DateTime
+}
+
+type webhookPayload struct {
+	Content  json.RawMessage
+	HashCode string
+}
+
+// WebhookHandler receives webhook calls from Exact Online. Calls are signed
+// with the client secret of the app; calls with a wrong signature are
+// refused. Valid events are passed to the functions registered for their
+// topic with Handle.
+type WebhookHandler struct {
+	Secret string
+
+	mu       sync.RWMutex
+	handlers map[string][]func(context.Context, WebhookEvent) error
+}
+
+// NewWebhookHandler creates a WebhookHandler verifying calls with secret
+func NewWebhookHandler(secret string) *WebhookHandler {
+	return &WebhookHandler{
+		Secret:   secret,
+		handlers: make(map[string][]func(context.Context, WebhookEvent) error),
+	}
+}
+
+// Handle registers fn for events of topic
+func (h *WebhookHandler) Handle(topic string, fn func(context.Context, WebhookEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.handlers == nil {
+		h.handlers = make(map[string][]func(context.Context, WebhookEvent) error)
+	}
+	h.handlers[topic] = append(h.handlers[topic], fn)
+}
+
+func (h *WebhookHandler) validSignature(content []byte, hashCode string) bool {
+	mac := hmac.New(sha256.New, []byte(h.Secret))
+	mac.Write(content)
+	expected := mac.Sum(nil)
+	given, err := hex.DecodeString(strings.TrimSpace(hashCode))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, given)
+}
+
+// maxWebhookBody is the largest webhook call that is read. Exact Online only
+// sends the key of what changed, so real calls are far smaller.
+const maxWebhookBody = 64 << 10
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBody {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	var p webhookPayload
+	if err := json.Unmarshal(body, &p); err != nil || len(p.Content) == 0 {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if !h.validSignature(p.Content, p.HashCode) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var ev WebhookEvent
+	if err := json.Unmarshal(p.Content, &ev); err != nil {
+		http.Error(w, "invalid content", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	fns := h.handlers[ev.Topic]
+	h.mu.RUnlock()
+	for _, fn := range fns {
+		// Exact Online retries calls that are not answered with a 200
+		if err := fn(r.Context(), ev); err != nil {
+			http.Error(w, "handler failed", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,152 @@
+package exactonline
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Recras/exactonline/odata2json"
+	"golang.org/x/oauth2"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			if r.URL.Path != "/api/v1/123/webhooks/WebhookSubscriptions" {
+				t.Errorf("Unexpected path %#v", r.URL.Path)
+			}
+			fmt.Fprint(w, `{"d":{"results":[{"ID":"sub-guid","CallbackURL":"https://example.com/hook","Topic":"SalesEntries"}]}}`)
+		case "POST":
+			var s WebhookSubscription
+			json.NewDecoder(r.Body).Decode(&s)
+			if s.Topic != "Accounts" || s.CallbackURL != "https://example.com/hook" {
+				t.Errorf("Unexpected subscription %#v", s)
+			}
+			w.WriteHeader(201)
+			fmt.Fprint(w, `{"d":{"ID":"new-guid","CallbackURL":"https://example.com/hook","Topic":"Accounts"}}`)
+		case "DELETE":
+			if r.URL.Path != "/api/v1/123/webhooks/WebhookSubscriptions(guid'sub-guid')" {
+				t.Errorf("Unexpected path %#v", r.URL.Path)
+			}
+			w.WriteHeader(204)
+		}
+	}))
+	defer ts.Close()
+
+	c := Config{BaseURL: ts.URL}
+	cl := c.NewClient(oauth2.Token{Expiry: time.Now().Add(time.Minute)})
+	cl.division = 123
+
+	subs, err := cl.GetWebhookSubscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %#v", err)
+	}
+	if len(subs) != 1 || subs[0].ID != "sub-guid" {
+		t.Errorf("Expected 1 subscription, got %#v", subs)
+	}
+
+	s := WebhookSubscription{Topic: "Accounts"}
+	if err := s.Save(context.Background(), cl); err != ErrWebhookSubscriptionCallbackURLRequired {
+		t.Errorf("Expected ErrWebhookSubscriptionCallbackURLRequired, got %#v", err)
+	}
+	s.CallbackURL = "https://example.com/hook"
+	if err := s.Save(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+	if s.ID != "new-guid" {
+		t.Errorf("Expected ID to be set after save, got %#v", s.ID)
+	}
+
+	if err := subs[0].Delete(context.Background(), cl); err != nil {
+		t.Errorf("Expected no error, got %#v", err)
+	}
+}
+
+func signedPayload(secret, content string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return `{"Content":` + content + `,"HashCode":"` + strings.ToUpper(hex.EncodeToString(mac.Sum(nil))) + `"}`
+}
+
+func TestWebhookHandler(t *testing.T) {
+	content := `{"Topic":"SalesEntries","ClientId":"client-guid","Division":123,"Action":"Delete","Key":"entry-guid","EventCreatedOn":"2016-05-27T10:55:57.663"}`
+
+	var got []WebhookEvent
+	h := NewWebhookHandler("s3cr1t")
+	h.Handle("SalesEntries", func(ctx context.Context, ev WebhookEvent) error {
+		got = append(got, ev)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/exact", strings.NewReader(signedPayload("s3cr1t", content))))
+	if w.Code != 200 {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected handler to be called once, got %d", len(got))
+	}
+	if got[0].Division != 123 || got[0].Key != "entry-guid" || got[0].Action != "Delete" {
+		t.Errorf("Unexpected event %#v", got[0])
+	}
+	if created := time.Date(2016, 5, 27, 10, 55, 57, 663e6, odata2json.Amsterdam); !got[0].EventCreatedOn.Time.Equal(created) {
+		t.Errorf("Expected EventCreatedOn to be %s, got %s", created, got[0].EventCreatedOn.Time)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/exact", strings.NewReader(signedPayload("wrong", content))))
+	if w.Code != 401 {
+		t.Errorf("Expected status 401 for wrong signature, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/exact", strings.NewReader(`{`)))
+	if w.Code != 400 {
+		t.Errorf("Expected status 400 for invalid payload, got %d", w.Code)
+	}
+	if len(got) != 1 {
+		t.Errorf("Expected handler not to be called for refused calls")
+	}
+}
+
+func TestWebhookHandler_HandlerError(t *testing.T) {
+	content := `{"Topic":"Accounts","Division":123,"Action":"Update","Key":"account-guid"}`
+	h := NewWebhookHandler("s3cr1t")
+	h.Handle("Accounts", func(ctx context.Context, ev WebhookEvent) error {
+		return fmt.Errorf("database unavailable")
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/exact", strings.NewReader(signedPayload("s3cr1t", content))))
+	if w.Code != 500 {
+		t.Errorf("Expected status 500 so Exact Online retries, got %d", w.Code)
+	}
+}
+
+func TestWebhookHandler_TooLarge(t *testing.T) {
+	called := false
+	h := NewWebhookHandler("s3cr1t")
+	h.Handle("Accounts", func(ctx context.Context, ev WebhookEvent) error {
+		called = true
+		return nil
+	})
+
+	content := `{"Topic":"Accounts","Division":123,"Action":"Update","Key":"` + strings.Repeat("a", maxWebhookBody) + `"}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/exact", strings.NewReader(signedPayload("s3cr1t", content))))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+	if called {
+		t.Errorf("Expected the handler not to be called")
+	}
+}